@@ -0,0 +1,54 @@
+package dh
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"errors"
+	"math/big"
+)
+
+const nistPrimeHex = "ffffffffffffffffc90fdaa22168c234c4c6628b80dc1cd129024" +
+	"e088a67cc74020bbea63b139b22514a08798e3404ddef9519b3cd3a431b" +
+	"302b0a6df25f14374fe1356d6d51c245e485b576625e7ec6f44c42e9a63" +
+	"7ed6b0bff5cb6f406b7edee386bfb5a899fa5ae9f24117c4b1fe649286651" +
+	"ece45b3dc2007cb8a163bf0598da48361c55d39a69163fa8fd24cf5f8365" +
+	"5d23dca3ad961c62f356208552bb9ed529077096966d670c354e4abc9804" +
+	"f1746c08ca237327ffffffffffffffff"
+
+var (
+	// P is the 1536-bit MODP prime used by the NIST/cryptopals DH challenges
+	P, _ = new(big.Int).SetString(nistPrimeHex, 16)
+
+	// G is the generator used along with P
+	G = big.NewInt(2)
+)
+
+// GenerateKeyPair - returns a random private key a in [1, p-1) and its
+// matching public key g^a mod p
+func GenerateKeyPair(p, g *big.Int) (*big.Int, *big.Int, error) {
+	if p.Cmp(big.NewInt(2)) <= 0 {
+		return nil, nil, errors.New("invalid DH prime")
+	}
+
+	privateKey, err := rand.Int(rand.Reader, new(big.Int).Sub(p, big.NewInt(2)))
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey.Add(privateKey, big.NewInt(1))
+
+	publicKey := new(big.Int).Exp(g, privateKey, p)
+	return privateKey, publicKey, nil
+}
+
+// SharedSecret - computes the shared secret (publicKey^privateKey mod p)
+// from the peer public key and our own private key
+func SharedSecret(publicKey, privateKey, p *big.Int) *big.Int {
+	return new(big.Int).Exp(publicKey, privateKey, p)
+}
+
+// DeriveKey - derives a symmetric key of keySize bytes (up to SHA-1
+// output size) by hashing the shared secret with SHA-1
+func DeriveKey(sharedSecret *big.Int, keySize int) []byte {
+	digest := sha1.Sum(sharedSecret.Bytes())
+	return digest[:keySize]
+}
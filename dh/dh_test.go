@@ -0,0 +1,36 @@
+package dh
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSharedSecret(t *testing.T) {
+	groups := []struct {
+		p, g *big.Int
+	}{
+		{big.NewInt(37), big.NewInt(5)},
+		{P, G},
+	}
+
+	for _, group := range groups {
+		// given
+		privateA, publicA, err := GenerateKeyPair(group.p, group.g)
+		if err != nil {
+			t.Fatalf("Error generating key pair: %s", err.Error())
+		}
+		privateB, publicB, err := GenerateKeyPair(group.p, group.g)
+		if err != nil {
+			t.Fatalf("Error generating key pair: %s", err.Error())
+		}
+
+		// when
+		secretA := SharedSecret(publicB, privateA, group.p)
+		secretB := SharedSecret(publicA, privateB, group.p)
+
+		// then
+		if secretA.Cmp(secretB) != 0 {
+			t.Errorf("SharedSecret(...) mismatch for p=%s: %s != %s", group.p, secretA, secretB)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package dsa
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// Parameters - DSA domain parameters
+type Parameters struct {
+	P *big.Int
+	Q *big.Int
+	G *big.Int
+}
+
+// PublicKey - DSA public key, y = g^x mod p
+type PublicKey struct {
+	Parameters
+	Y *big.Int
+}
+
+// PrivateKey - DSA private key
+type PrivateKey struct {
+	PublicKey
+	X *big.Int
+}
+
+func mustParseHex(hexData string) *big.Int {
+	n, ok := new(big.Int).SetString(hexData, 16)
+	if !ok {
+		panic("invalid hex number " + hexData)
+	}
+	return n
+}
+
+// DefaultParameters - DSA parameters used by the cryptopals challenges
+var DefaultParameters = Parameters{
+	P: mustParseHex("800000000000000089e1855218a0e7dac38136ffafa72eda7" +
+		"859f2171e25e65eac698c1702578b07dc2a1076da241c76c6" +
+		"2d374d8389ea5aeffd3226a0530cc565f3bf6b50929139ebe" +
+		"ac04f48c3c84afb796d61e5a4f9a8fda812ab59494232c7d2" +
+		"b4deb50aa18ee9e132bfa85ac4374d7f9091abc3d015efc87" +
+		"1a584471bb1"),
+	Q: mustParseHex("f4f47f05794b256174bba6e9b396a7707e563c5b"),
+	G: mustParseHex("5958c9d3898b224b12672c0b98e06c60df923cb8bc999d119" +
+		"458fef538b8fa4046c8db53039db620c094c9fa077ef389b5" +
+		"322a559946a71903f990f1f7e0e025e2d7f7cf494aff1a047" +
+		"0f5b64c36b625a097f1651fe775323556fe00b3608c887892" +
+		"878480e99041be601a62166ca6894bdd41a7054ec89f756ba" +
+		"9fc95302291"),
+}
+
+// randomScalar - returns a random number in [1, q)
+func randomScalar(q *big.Int) (*big.Int, error) {
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(q, big.NewInt(1)))
+	if err != nil {
+		return nil, err
+	}
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+// GenerateKey - generates a new key pair for the given parameters
+func GenerateKey(params Parameters) (*PrivateKey, error) {
+	x, err := randomScalar(params.Q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrivateKey{
+		PublicKey: PublicKey{Parameters: params, Y: new(big.Int).Exp(params.G, x, params.P)},
+		X:         x,
+	}, nil
+}
+
+// Sign - signs the message hash with a fresh random nonce, returns (r, s)
+func Sign(privateKey *PrivateKey, hash []byte) (*big.Int, *big.Int, error) {
+	for {
+		k, err := randomScalar(privateKey.Q)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		r, s, err := SignWithNonce(privateKey, hash, k)
+		if err == nil {
+			return r, s, nil
+		}
+	}
+}
+
+// SignWithNonce - signs the message hash using the nonce k, which
+// must be secret and never be reused
+func SignWithNonce(privateKey *PrivateKey, hash []byte, k *big.Int) (*big.Int, *big.Int, error) {
+	q := privateKey.Q
+
+	// r = (g^k mod p) mod q
+	r := new(big.Int).Exp(privateKey.G, k, privateKey.P)
+	r.Mod(r, q)
+
+	// s = k^-1 (H(m) + x*r) mod q
+	kInverse := new(big.Int).ModInverse(k, q)
+	if kInverse == nil {
+		return nil, nil, errors.New("nonce is not invertible")
+	}
+
+	s := new(big.Int).Mul(privateKey.X, r)
+	s.Add(s, new(big.Int).SetBytes(hash))
+	s.Mul(s, kInverse)
+	s.Mod(s, q)
+
+	if r.Sign() == 0 || s.Sign() == 0 {
+		return nil, nil, errors.New("invalid nonce, r or s is zero")
+	}
+
+	return r, s, nil
+}
+
+// Verify - verifies the signature (r, s) of the message hash
+func Verify(publicKey *PublicKey, hash []byte, r, s *big.Int) bool {
+	if r.Sign() <= 0 || r.Cmp(publicKey.Q) >= 0 || s.Sign() <= 0 || s.Cmp(publicKey.Q) >= 0 {
+		return false
+	}
+
+	return VerifyUnchecked(publicKey, hash, r, s)
+}
+
+// VerifyUnchecked - verifies the signature (r, s) of the message hash
+// without checking that r and s are within (0, q)
+func VerifyUnchecked(publicKey *PublicKey, hash []byte, r, s *big.Int) bool {
+	p, q := publicKey.P, publicKey.Q
+
+	w := new(big.Int).ModInverse(s, q)
+	if w == nil {
+		return false
+	}
+
+	// u1 = H(m)*w mod q, u2 = r*w mod q
+	u1 := new(big.Int).Mul(new(big.Int).SetBytes(hash), w)
+	u1.Mod(u1, q)
+	u2 := new(big.Int).Mul(r, w)
+	u2.Mod(u2, q)
+
+	// v = (g^u1 * y^u2 mod p) mod q
+	v := new(big.Int).Exp(publicKey.G, u1, p)
+	v.Mul(v, new(big.Int).Exp(publicKey.Y, u2, p))
+	v.Mod(v, p)
+	v.Mod(v, q)
+
+	return v.Cmp(r) == 0
+}
@@ -0,0 +1,30 @@
+package dsa
+
+import (
+	"crypto/sha1"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	// given
+	privateKey, err := GenerateKey(DefaultParameters)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	hash := sha1.Sum([]byte("YELLOW SUBMARINE"))
+	otherHash := sha1.Sum([]byte("YELLOW SUBMARINF"))
+
+	// when
+	r, s, err := Sign(privateKey, hash[:])
+	if err != nil {
+		t.Fatalf("Error signing: %s", err.Error())
+	}
+
+	// then
+	if !Verify(&privateKey.PublicKey, hash[:], r, s) {
+		t.Errorf("Verify(...) rejected a valid signature")
+	}
+	if Verify(&privateKey.PublicKey, otherHash[:], r, s) {
+		t.Errorf("Verify(...) accepted a signature for a different message")
+	}
+}
@@ -0,0 +1,221 @@
+package ec
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// BigFromString - parses a decimal constant
+func BigFromString(s string) *big.Int {
+	n, _ := new(big.Int).SetString(s, 10)
+	return n
+}
+
+// CryptopalsCurve - y^2 = x^3 - 95051x + 11279326 curve used by the
+// cryptopals ECDH challenges, its order is 8 * N
+var CryptopalsCurve = &Curve{
+	P: BigFromString("233970423115425145524320034830162017933"),
+	A: big.NewInt(-95051),
+	B: big.NewInt(11279326),
+	G: Point{big.NewInt(182), BigFromString("85518893674295321206118380980485522083")},
+	N: BigFromString("29246302889428143187362802287225875743"),
+}
+
+// Point - affine point, the point at infinity has nil coordinates
+type Point struct {
+	X, Y *big.Int
+}
+
+// Infinity - returns the point at infinity
+func Infinity() Point {
+	return Point{}
+}
+
+// IsInfinity - returns whether the point is the point at infinity
+func (p Point) IsInfinity() bool {
+	return p.X == nil
+}
+
+// Equal - returns whether both points are the same
+func (p Point) Equal(q Point) bool {
+	if p.IsInfinity() || q.IsInfinity() {
+		return p.IsInfinity() == q.IsInfinity()
+	}
+	return p.X.Cmp(q.X) == 0 && p.Y.Cmp(q.Y) == 0
+}
+
+// Curve - short Weierstrass curve y^2 = x^3 + ax + b over GF(P), with a base
+// point G of order N
+type Curve struct {
+	P, A, B *big.Int
+	G       Point
+	N       *big.Int
+}
+
+// WithB - returns the curve sharing P and A but with a different b, as
+// addition formulas never use b points of that curve can be fed to any code
+// working on c that does not validate its inputs
+func (c *Curve) WithB(b *big.Int) *Curve {
+	return &Curve{P: c.P, A: c.A, B: b}
+}
+
+// IsOnCurve - returns whether p satisfies the curve equation
+func (c *Curve) IsOnCurve(p Point) bool {
+	if p.IsInfinity() {
+		return true
+	}
+	if p.X.Sign() < 0 || p.X.Cmp(c.P) >= 0 || p.Y.Sign() < 0 || p.Y.Cmp(c.P) >= 0 {
+		return false
+	}
+
+	y2 := new(big.Int).Mul(p.Y, p.Y)
+	y2.Mod(y2, c.P)
+	return y2.Cmp(c.rhs(p.X)) == 0
+}
+
+// rhs - returns x^3 + ax + b mod p
+func (c *Curve) rhs(x *big.Int) *big.Int {
+	r := new(big.Int).Mul(x, x)
+	r.Add(r, c.A)
+	r.Mul(r, x)
+	r.Add(r, c.B)
+	return r.Mod(r, c.P)
+}
+
+// ValidatePoint - returns an error if p is not a point of the curve other
+// than the point at infinity
+func (c *Curve) ValidatePoint(p Point) error {
+	if p.IsInfinity() {
+		return errors.New("point at infinity")
+	}
+	if !c.IsOnCurve(p) {
+		return errors.New("point not on curve")
+	}
+	return nil
+}
+
+// Neg - returns -p
+func (c *Curve) Neg(p Point) Point {
+	if p.IsInfinity() {
+		return p
+	}
+	return Point{new(big.Int).Set(p.X), new(big.Int).Mod(new(big.Int).Neg(p.Y), c.P)}
+}
+
+// Add - returns p1 + p2
+func (c *Curve) Add(p1, p2 Point) Point {
+	if p1.IsInfinity() {
+		return p2
+	}
+	if p2.IsInfinity() {
+		return p1
+	}
+	if p1.X.Cmp(p2.X) == 0 {
+		sum := new(big.Int).Add(p1.Y, p2.Y)
+		if sum.Mod(sum, c.P).Sign() == 0 {
+			return Infinity()
+		}
+	}
+
+	var slope *big.Int
+	if p1.Equal(p2) {
+		// (3x^2 + a) / 2y
+		numerator := new(big.Int).Mul(p1.X, p1.X)
+		numerator.Mul(numerator, big.NewInt(3))
+		numerator.Add(numerator, c.A)
+		denominator := new(big.Int).Lsh(p1.Y, 1)
+		slope = numerator.Mul(numerator, denominator.ModInverse(denominator, c.P))
+	} else {
+		// (y2 - y1) / (x2 - x1)
+		numerator := new(big.Int).Sub(p2.Y, p1.Y)
+		denominator := new(big.Int).Sub(p2.X, p1.X)
+		denominator.Mod(denominator, c.P)
+		slope = numerator.Mul(numerator, denominator.ModInverse(denominator, c.P))
+	}
+	slope.Mod(slope, c.P)
+
+	x := new(big.Int).Mul(slope, slope)
+	x.Sub(x, p1.X)
+	x.Sub(x, p2.X)
+	x.Mod(x, c.P)
+
+	y := new(big.Int).Sub(p1.X, x)
+	y.Mul(y, slope)
+	y.Sub(y, p1.Y)
+	y.Mod(y, c.P)
+
+	return Point{x, y}
+}
+
+// ScalarMult - returns k * p (double and add), negative scalars multiply -p
+func (c *Curve) ScalarMult(p Point, k *big.Int) Point {
+	if k.Sign() < 0 {
+		return c.ScalarMult(c.Neg(p), new(big.Int).Neg(k))
+	}
+
+	result := Infinity()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		result = c.Add(result, result)
+		if k.Bit(i) == 1 {
+			result = c.Add(result, p)
+		}
+	}
+	return result
+}
+
+// ScalarBaseMult - returns k * G
+func (c *Curve) ScalarBaseMult(k *big.Int) Point {
+	return c.ScalarMult(c.G, k)
+}
+
+// PointAt - returns one of the points of x-coordinate x, or false if x is
+// not the coordinate of a curve point
+func (c *Curve) PointAt(x *big.Int) (Point, bool) {
+	y := new(big.Int).ModSqrt(c.rhs(x), c.P)
+	if y == nil {
+		return Infinity(), false
+	}
+	return Point{new(big.Int).Set(x), y}, true
+}
+
+// RandomPoint - returns a random point of the curve other than infinity
+func (c *Curve) RandomPoint() (Point, error) {
+	for {
+		x, err := rand.Int(rand.Reader, c.P)
+		if err != nil {
+			return Point{}, err
+		}
+
+		if p, ok := c.PointAt(x); ok {
+			return p, nil
+		}
+	}
+}
+
+// GenerateKey - returns a random private key in [1, N) and its public key
+func GenerateKey(c *Curve) (*big.Int, Point, error) {
+	privateKey, err := rand.Int(rand.Reader, new(big.Int).Sub(c.N, big.NewInt(1)))
+	if err != nil {
+		return nil, Point{}, err
+	}
+	privateKey.Add(privateKey, big.NewInt(1))
+
+	return privateKey, c.ScalarBaseMult(privateKey), nil
+}
+
+// SharedSecret - returns the ECDH shared point privateKey * publicKey, the
+// peer public key is checked to be on the curve unless validate is false
+func SharedSecret(c *Curve, privateKey *big.Int, publicKey Point, validate bool) (Point, error) {
+	if validate {
+		if err := c.ValidatePoint(publicKey); err != nil {
+			return Point{}, err
+		}
+	}
+
+	secret := c.ScalarMult(publicKey, privateKey)
+	if validate && secret.IsInfinity() {
+		return Point{}, errors.New("invalid shared secret")
+	}
+	return secret, nil
+}
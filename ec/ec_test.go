@@ -0,0 +1,80 @@
+package ec
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestScalarBaseMult(t *testing.T) {
+	// given
+	c := CryptopalsCurve
+
+	// when
+	identity := c.ScalarBaseMult(c.N)
+	sum := c.Add(c.ScalarBaseMult(big.NewInt(1000)), c.ScalarBaseMult(big.NewInt(337)))
+
+	// then
+	if !c.IsOnCurve(c.G) {
+		t.Errorf("IsOnCurve(G) = false, expected true")
+	}
+	if !identity.IsInfinity() {
+		t.Errorf("ScalarBaseMult(N) = (%s, %s), expected infinity", identity.X, identity.Y)
+	}
+	if expected := c.ScalarBaseMult(big.NewInt(1337)); !sum.Equal(expected) {
+		t.Errorf("1000G + 337G = (%s, %s), expected (%s, %s)", sum.X, sum.Y, expected.X, expected.Y)
+	}
+}
+
+func TestSharedSecret(t *testing.T) {
+	// given
+	c := CryptopalsCurve
+	alicePrivate, alicePublic, err := GenerateKey(c)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	bobPrivate, bobPublic, err := GenerateKey(c)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+
+	// when
+	aliceSecret, err := SharedSecret(c, alicePrivate, bobPublic, true)
+	if err != nil {
+		t.Fatalf("Error computing shared secret: %s", err.Error())
+	}
+	bobSecret, err := SharedSecret(c, bobPrivate, alicePublic, true)
+	if err != nil {
+		t.Fatalf("Error computing shared secret: %s", err.Error())
+	}
+	_, invalidErr := SharedSecret(c, bobPrivate, Point{big.NewInt(1), big.NewInt(1)}, true)
+
+	// then
+	if !aliceSecret.Equal(bobSecret) {
+		t.Errorf("SharedSecret(...) differ between Alice and Bob")
+	}
+	if invalidErr == nil {
+		t.Errorf("SharedSecret(point not on curve) succeeded, expected an error")
+	}
+}
+
+func TestLadder(t *testing.T) {
+	// given
+	m := CryptopalsMontgomeryCurve
+	w := m.Weierstrass()
+	k := big.NewInt(123456789)
+
+	// when
+	u := m.Ladder(m.U, k)
+	identity := m.Ladder(m.U, m.N)
+
+	// then
+	if w.A.Cmp(new(big.Int).Mod(CryptopalsCurve.A, w.P)) != 0 || w.B.Cmp(CryptopalsCurve.B) != 0 {
+		t.Errorf("Weierstrass() = (a=%s, b=%s), expected (a=%s, b=%s)", w.A, w.B, CryptopalsCurve.A, CryptopalsCurve.B)
+	}
+	if expected := CryptopalsCurve.ScalarBaseMult(k); u.Cmp(m.ToMontgomeryU(expected.X)) != 0 {
+		t.Errorf("Ladder(U, %s) = %s, expected %s", k, u, m.ToMontgomeryU(expected.X))
+	}
+	if identity.Sign() != 0 {
+		t.Errorf("Ladder(U, N) = %s, expected 0", identity)
+	}
+}
@@ -0,0 +1,158 @@
+package ec
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// MontgomeryCurve - Montgomery curve Bv^2 = u^3 + Au^2 + u over GF(P), with
+// a base point of u-coordinate U and order N
+type MontgomeryCurve struct {
+	P, A, B *big.Int
+	U       *big.Int
+	N       *big.Int
+}
+
+// CryptopalsMontgomeryCurve - Montgomery form of CryptopalsCurve,
+// v^2 = u^3 + 534u^2 + u, its base point maps to CryptopalsCurve.G
+var CryptopalsMontgomeryCurve = &MontgomeryCurve{
+	P: CryptopalsCurve.P,
+	A: big.NewInt(534),
+	B: big.NewInt(1),
+	U: big.NewInt(4),
+	N: CryptopalsCurve.N,
+}
+
+// cswap - swaps a and b when bit is 1
+func cswap(a, b *big.Int, bit uint) (*big.Int, *big.Int) {
+	if bit == 1 {
+		return b, a
+	}
+	return a, b
+}
+
+// Ladder - returns the u-coordinate of k times the point of u-coordinate u
+// with the Montgomery ladder, the point at infinity maps to 0. Works for any
+// u, the point being on the curve or on its quadratic twist
+func (c *MontgomeryCurve) Ladder(u, k *big.Int) *big.Int {
+	p := c.P
+	mod := func(x *big.Int) *big.Int { return x.Mod(x, p) }
+
+	u2, w2 := big.NewInt(1), big.NewInt(0)
+	u3, w3 := new(big.Int).Set(u), big.NewInt(1)
+
+	for i := p.BitLen() - 1; i >= 0; i-- {
+		bit := k.Bit(i)
+		u2, u3 = cswap(u2, u3, bit)
+		w2, w3 = cswap(w2, w3, bit)
+
+		// u3, w3 = (u2*u3 - w2*w3)^2, u * (u2*w3 - w2*u3)^2
+		t1 := mod(new(big.Int).Sub(new(big.Int).Mul(u2, u3), new(big.Int).Mul(w2, w3)))
+		t2 := mod(new(big.Int).Sub(new(big.Int).Mul(u2, w3), new(big.Int).Mul(w2, u3)))
+		newU3 := mod(t1.Mul(t1, t1))
+		newW3 := mod(t2.Mul(t2, t2).Mul(t2, u))
+
+		// u2, w2 = (u2^2 - w2^2)^2, 4*u2*w2 * (u2^2 + A*u2*w2 + w2^2)
+		u2u2 := new(big.Int).Mul(u2, u2)
+		w2w2 := new(big.Int).Mul(w2, w2)
+		u2w2 := new(big.Int).Mul(u2, w2)
+		t3 := mod(new(big.Int).Sub(u2u2, w2w2))
+		newU2 := mod(t3.Mul(t3, t3))
+		t4 := new(big.Int).Add(u2u2, w2w2)
+		t4.Add(t4, new(big.Int).Mul(c.A, u2w2))
+		newW2 := mod(t4.Mul(t4, u2w2).Lsh(t4, 2))
+
+		u2, w2, u3, w3 = newU2, newW2, newU3, newW3
+		u2, u3 = cswap(u2, u3, bit)
+		w2, w3 = cswap(w2, w3, bit)
+	}
+
+	inverse := new(big.Int).ModInverse(w2, p)
+	if inverse == nil {
+		return big.NewInt(0)
+	}
+	return mod(u2.Mul(u2, inverse))
+}
+
+// rhs - returns u^3 + Au^2 + u mod p
+func (c *MontgomeryCurve) rhs(u *big.Int) *big.Int {
+	r := new(big.Int).Add(u, c.A)
+	r.Mul(r, u)
+	r.Add(r, big.NewInt(1))
+	r.Mul(r, u)
+	return r.Mod(r, c.P)
+}
+
+// isSquare - returns whether x is a square mod p
+func (c *MontgomeryCurve) isSquare(x *big.Int) bool {
+	return big.Jacobi(x, c.P) >= 0
+}
+
+// IsOnCurve - returns whether u is the u-coordinate of a curve point, it
+// belongs to the quadratic twist otherwise
+func (c *MontgomeryCurve) IsOnCurve(u *big.Int) bool {
+	// B v^2 = rhs has a solution when rhs / B is a square
+	v2 := new(big.Int).ModInverse(c.B, c.P)
+	v2.Mul(v2, c.rhs(u))
+	return c.isSquare(v2.Mod(v2, c.P))
+}
+
+// RandomTwistU - returns a random u-coordinate of a point of the quadratic
+// twist of the curve
+func (c *MontgomeryCurve) RandomTwistU() (*big.Int, error) {
+	for {
+		u, err := rand.Int(rand.Reader, c.P)
+		if err != nil {
+			return nil, err
+		}
+		if !c.IsOnCurve(u) {
+			return u, nil
+		}
+	}
+}
+
+// third - returns x / 3 mod p
+func (c *MontgomeryCurve) third(x *big.Int) *big.Int {
+	r := new(big.Int).ModInverse(big.NewInt(3), c.P)
+	r.Mul(r, x)
+	return r.Mod(r, c.P)
+}
+
+// Weierstrass - returns the equivalent short Weierstrass curve, mapping
+// (u, v) to ((u + A/3) / B, v / B)
+func (c *MontgomeryCurve) Weierstrass() *Curve {
+	p := c.P
+	bInv := new(big.Int).ModInverse(c.B, p)
+	bInv2 := new(big.Int).Mul(bInv, bInv)
+
+	// a = (3 - A^2) / 3B^2
+	a := new(big.Int).Sub(big.NewInt(3), new(big.Int).Mul(c.A, c.A))
+	a = c.third(a.Mul(a, bInv2))
+
+	// b = (2A^3 - 9A) / 27B^3
+	b := new(big.Int).Mul(c.A, c.A)
+	b.Mul(b, big.NewInt(2))
+	b.Sub(b, big.NewInt(9))
+	b.Mul(b, c.A)
+	b.Mul(b, bInv2)
+	b.Mul(b, bInv)
+	b = c.third(c.third(c.third(b)))
+
+	w := &Curve{P: p, A: a, B: b, N: c.N}
+	w.G, _ = w.PointAt(c.ToWeierstrassX(c.U))
+	return w
+}
+
+// ToWeierstrassX - returns the Weierstrass x-coordinate of u
+func (c *MontgomeryCurve) ToWeierstrassX(u *big.Int) *big.Int {
+	x := new(big.Int).Add(u, c.third(c.A))
+	x.Mul(x, new(big.Int).ModInverse(c.B, c.P))
+	return x.Mod(x, c.P)
+}
+
+// ToMontgomeryU - returns the Montgomery u-coordinate of the Weierstrass x
+func (c *MontgomeryCurve) ToMontgomeryU(x *big.Int) *big.Int {
+	u := new(big.Int).Mul(x, c.B)
+	u.Sub(u, c.third(c.A))
+	return u.Mod(u, c.P)
+}
@@ -0,0 +1,124 @@
+package ecdsa
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"github.com/ka3de/go-cryptochallenges/ec"
+)
+
+// PublicKey - ECDSA public key Q = dG, the curve holds the generator G
+type PublicKey struct {
+	Curve *ec.Curve
+	Q     ec.Point
+}
+
+// PrivateKey - ECDSA private key
+type PrivateKey struct {
+	PublicKey
+	D *big.Int
+}
+
+// randomScalar - returns a random number in [1, n)
+func randomScalar(n *big.Int) (*big.Int, error) {
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(n, big.NewInt(1)))
+	if err != nil {
+		return nil, err
+	}
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+// GenerateKey - generates a new key pair on curve
+func GenerateKey(curve *ec.Curve) (*PrivateKey, error) {
+	d, err := randomScalar(curve.N)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrivateKey{
+		PublicKey: PublicKey{Curve: curve, Q: curve.ScalarBaseMult(d)},
+		D:         d,
+	}, nil
+}
+
+// HashToInt - returns the message hash as an integer, truncated to the bit
+// length of the curve order n
+func HashToInt(hash []byte, n *big.Int) *big.Int {
+	e := new(big.Int).SetBytes(hash)
+	if excess := len(hash)*8 - n.BitLen(); excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
+
+// Sign - signs the message hash with a fresh random nonce, returns (r, s)
+func Sign(privateKey *PrivateKey, hash []byte) (*big.Int, *big.Int, error) {
+	for {
+		k, err := randomScalar(privateKey.Curve.N)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		r, s, err := SignWithNonce(privateKey, hash, k)
+		if err == nil {
+			return r, s, nil
+		}
+	}
+}
+
+// SignWithNonce - signs the message hash using the nonce k, which must be
+// secret, uniformly random and never be reused
+func SignWithNonce(privateKey *PrivateKey, hash []byte, k *big.Int) (*big.Int, *big.Int, error) {
+	n := privateKey.Curve.N
+
+	// r = x(kG) mod n
+	point := privateKey.Curve.ScalarBaseMult(k)
+	if point.IsInfinity() {
+		return nil, nil, errors.New("invalid nonce")
+	}
+	r := new(big.Int).Mod(point.X, n)
+
+	// s = k^-1 (H(m) + d*r) mod n
+	kInverse := new(big.Int).ModInverse(k, n)
+	if kInverse == nil {
+		return nil, nil, errors.New("nonce is not invertible")
+	}
+
+	s := new(big.Int).Mul(privateKey.D, r)
+	s.Add(s, HashToInt(hash, n))
+	s.Mul(s, kInverse)
+	s.Mod(s, n)
+
+	if r.Sign() == 0 || s.Sign() == 0 {
+		return nil, nil, errors.New("invalid nonce, r or s is zero")
+	}
+
+	return r, s, nil
+}
+
+// Verify - verifies the signature (r, s) of the message hash
+func Verify(publicKey *PublicKey, hash []byte, r, s *big.Int) bool {
+	curve := publicKey.Curve
+	n := curve.N
+	if r.Sign() <= 0 || r.Cmp(n) >= 0 || s.Sign() <= 0 || s.Cmp(n) >= 0 {
+		return false
+	}
+
+	// u1 = H(m)/s mod n, u2 = r/s mod n
+	w := new(big.Int).ModInverse(s, n)
+	if w == nil {
+		return false
+	}
+	u1 := new(big.Int).Mul(HashToInt(hash, n), w)
+	u1.Mod(u1, n)
+	u2 := new(big.Int).Mul(r, w)
+	u2.Mod(u2, n)
+
+	// x(u1 G + u2 Q) mod n == r
+	point := curve.Add(curve.ScalarBaseMult(u1), curve.ScalarMult(publicKey.Q, u2))
+	if point.IsInfinity() {
+		return false
+	}
+	return new(big.Int).Mod(point.X, n).Cmp(r) == 0
+}
@@ -0,0 +1,32 @@
+package ecdsa
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ka3de/go-cryptochallenges/ec"
+)
+
+func TestSignVerify(t *testing.T) {
+	// given
+	privateKey, err := GenerateKey(ec.CryptopalsCurve)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	hash := sha256.Sum256([]byte("hi mom"))
+	otherHash := sha256.Sum256([]byte("hi dad"))
+
+	// when
+	r, s, err := Sign(privateKey, hash[:])
+	if err != nil {
+		t.Fatalf("Error signing message: %s", err.Error())
+	}
+
+	// then
+	if !Verify(&privateKey.PublicKey, hash[:], r, s) {
+		t.Errorf("Verify(...) = false for a valid signature, expected true")
+	}
+	if Verify(&privateKey.PublicKey, otherHash[:], r, s) {
+		t.Errorf("Verify(...) = true for another message, expected false")
+	}
+}
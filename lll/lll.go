@@ -0,0 +1,114 @@
+package lll
+
+import "math/big"
+
+// dot - returns the inner product of u and v
+func dot(u, v []*big.Rat) *big.Rat {
+	sum, product := new(big.Rat), new(big.Rat)
+	for i := range u {
+		sum.Add(sum, product.Mul(u[i], v[i]))
+	}
+	return sum
+}
+
+// round - returns the integer nearest to x, as a rational
+func round(x *big.Rat) *big.Rat {
+	// floor((2 * num + den) / (2 * den)), den being positive
+	numerator := new(big.Int).Lsh(x.Num(), 1)
+	numerator.Add(numerator, x.Denom())
+	denominator := new(big.Int).Lsh(x.Denom(), 1)
+	return new(big.Rat).SetInt(numerator.Div(numerator, denominator))
+}
+
+// Reduce - reduces in place the lattice basis given by the linearly
+// independent rows of basis with the Lenstra-Lenstra-Lovasz algorithm and
+// returns it. delta, in (1/4, 1), is the Lovasz condition parameter, 0.99
+// being the usual choice. Only the Gram-Schmidt coefficients mu and the
+// squared norms of the orthogonalized vectors are kept, and updated on swaps
+// instead of being recomputed
+func Reduce(basis [][]*big.Rat, delta *big.Rat) [][]*big.Rat {
+	n := len(basis)
+	if n < 2 {
+		return basis
+	}
+
+	// Gram-Schmidt orthogonalization
+	mu := make([][]*big.Rat, n)
+	norms := make([]*big.Rat, n)
+	orthogonal := make([][]*big.Rat, n)
+	for i := range basis {
+		mu[i] = make([]*big.Rat, n)
+		orthogonal[i] = make([]*big.Rat, len(basis[i]))
+		for c := range basis[i] {
+			orthogonal[i][c] = new(big.Rat).Set(basis[i][c])
+		}
+
+		for j := 0; j < i; j++ {
+			mu[i][j] = new(big.Rat).Quo(dot(basis[i], orthogonal[j]), norms[j])
+			for c := range orthogonal[i] {
+				orthogonal[i][c].Sub(orthogonal[i][c], new(big.Rat).Mul(mu[i][j], orthogonal[j][c]))
+			}
+		}
+		norms[i] = dot(orthogonal[i], orthogonal[i])
+	}
+
+	half := big.NewRat(1, 2)
+	product := new(big.Rat)
+
+	for k := 1; k < n; {
+		// size reduction of b_k
+		for j := k - 1; j >= 0; j-- {
+			if new(big.Rat).Abs(mu[k][j]).Cmp(half) <= 0 {
+				continue
+			}
+
+			q := round(mu[k][j])
+			for c := range basis[k] {
+				basis[k][c].Sub(basis[k][c], product.Mul(q, basis[j][c]))
+			}
+			mu[k][j].Sub(mu[k][j], q)
+			for i := 0; i < j; i++ {
+				mu[k][i].Sub(mu[k][i], product.Mul(q, mu[j][i]))
+			}
+		}
+
+		// Lovasz condition: |b*_k|^2 >= (delta - mu_k,k-1^2) |b*_k-1|^2
+		bound := new(big.Rat).Mul(mu[k][k-1], mu[k][k-1])
+		bound.Sub(delta, bound)
+		bound.Mul(bound, norms[k-1])
+		if norms[k].Cmp(bound) >= 0 {
+			k++
+			continue
+		}
+
+		// swap b_k and b_k-1, updating the Gram-Schmidt data
+		m := mu[k][k-1]
+		newNorm := new(big.Rat).Mul(m, m)
+		newNorm.Mul(newNorm, norms[k-1])
+		newNorm.Add(newNorm, norms[k])
+
+		newMu := new(big.Rat).Mul(m, norms[k-1])
+		newMu.Quo(newMu, newNorm)
+		newNormK := new(big.Rat).Mul(norms[k-1], norms[k])
+		newNormK.Quo(newNormK, newNorm)
+
+		basis[k], basis[k-1] = basis[k-1], basis[k]
+		for j := 0; j < k-1; j++ {
+			mu[k][j], mu[k-1][j] = mu[k-1][j], mu[k][j]
+		}
+		mu[k][k-1], norms[k], norms[k-1] = newMu, newNormK, newNorm
+
+		for i := k + 1; i < n; i++ {
+			t := mu[i][k]
+			updated := new(big.Rat).Mul(m, t)
+			mu[i][k] = updated.Sub(mu[i][k-1], updated)
+			mu[i][k-1] = new(big.Rat).Add(t, new(big.Rat).Mul(newMu, mu[i][k]))
+		}
+
+		if k > 1 {
+			k--
+		}
+	}
+
+	return basis
+}
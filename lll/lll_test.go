@@ -0,0 +1,45 @@
+package lll
+
+import (
+	"math/big"
+	"testing"
+)
+
+func ratMatrix(rows [][]string) [][]*big.Rat {
+	matrix := make([][]*big.Rat, len(rows))
+	for i, row := range rows {
+		matrix[i] = make([]*big.Rat, len(row))
+		for j, value := range row {
+			matrix[i][j], _ = new(big.Rat).SetString(value)
+		}
+	}
+	return matrix
+}
+
+func TestReduce(t *testing.T) {
+	// given
+	basis := ratMatrix([][]string{
+		{"-2", "0", "2", "0"},
+		{"1/2", "-1", "0", "0"},
+		{"-1", "0", "-2", "1/2"},
+		{"-1", "1", "1", "2"},
+	})
+	expected := ratMatrix([][]string{
+		{"1/2", "-1", "0", "0"},
+		{"-1", "0", "-2", "1/2"},
+		{"-1/2", "0", "1", "2"},
+		{"-3/2", "-1", "2", "0"},
+	})
+
+	// when
+	reduced := Reduce(basis, big.NewRat(99, 100))
+
+	// then
+	for i := range expected {
+		for j := range expected[i] {
+			if reduced[i][j].Cmp(expected[i][j]) != 0 {
+				t.Fatalf("Reduce(...) = %v, expected %v", reduced, expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package md4
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	// Size - size of an MD4 digest in bytes
+	Size = 16
+
+	// BlockSize - block size of MD4 in bytes
+	BlockSize = 64
+
+	// Steps - number of steps of the compression function
+	Steps = 48
+
+	round2Constant = 0x5a827999
+	round3Constant = 0x6ed9eba1
+)
+
+// InitialState - MD4 initial chaining value (a, b, c, d)
+var InitialState = [4]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476}
+
+// message word used and rotation amount of each step
+var (
+	stepWords = [Steps]int{
+		0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+		0, 4, 8, 12, 1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15,
+		0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15,
+	}
+	stepShifts = [Steps]int{
+		3, 7, 11, 19, 3, 7, 11, 19, 3, 7, 11, 19, 3, 7, 11, 19,
+		3, 5, 9, 13, 3, 5, 9, 13, 3, 5, 9, 13, 3, 5, 9, 13,
+		3, 9, 11, 15, 3, 9, 11, 15, 3, 9, 11, 15, 3, 9, 11, 15,
+	}
+)
+
+// F - round 1 boolean function
+func F(x, y, z uint32) uint32 {
+	return (x & y) | (^x & z)
+}
+
+// G - round 2 boolean function
+func G(x, y, z uint32) uint32 {
+	return (x & y) | (x & z) | (y & z)
+}
+
+// H - round 3 boolean function
+func H(x, y, z uint32) uint32 {
+	return x ^ y ^ z
+}
+
+// StepWord - returns the index of the message word used by step
+func StepWord(step int) int {
+	return stepWords[step]
+}
+
+// StepShift - returns the rotation amount of step
+func StepShift(step int) int {
+	return stepShifts[step]
+}
+
+// StepFunction - returns the boolean function and additive constant of step
+func StepFunction(step int) (func(x, y, z uint32) uint32, uint32) {
+	switch {
+	case step < 16:
+		return F, 0
+	case step < 32:
+		return G, round2Constant
+	default:
+		return H, round3Constant
+	}
+}
+
+// Chain - returns the chaining values computed by the compression function:
+// the first 4 are the input state in the (a, d, c, b) order, followed by the
+// value computed by each step (a1, d1, c1, b1, a2, ...). Step i computes
+// chain[i+4] = (chain[i] + f(chain[i+3], chain[i+2], chain[i+1]) + m + k) <<< s
+func Chain(state [4]uint32, block [16]uint32) [Steps + 4]uint32 {
+	var chain [Steps + 4]uint32
+	chain[0], chain[1], chain[2], chain[3] = state[0], state[3], state[2], state[1]
+
+	for step := 0; step < Steps; step++ {
+		f, k := StepFunction(step)
+		sum := chain[step] + f(chain[step+3], chain[step+2], chain[step+1]) + block[stepWords[step]] + k
+		chain[step+4] = bits.RotateLeft32(sum, stepShifts[step])
+	}
+
+	return chain
+}
+
+// Compress - returns the state after processing block from state
+func Compress(state [4]uint32, block [16]uint32) [4]uint32 {
+	chain := Chain(state, block)
+	return [4]uint32{
+		state[0] + chain[Steps],
+		state[1] + chain[Steps+3],
+		state[2] + chain[Steps+2],
+		state[3] + chain[Steps+1],
+	}
+}
+
+// DecodeBlock - returns the 16 little-endian words of a 64 bytes block
+func DecodeBlock(block []byte) [16]uint32 {
+	var words [16]uint32
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(block[4*i:])
+	}
+	return words
+}
+
+// EncodeBlock - returns the 64 bytes block of 16 words
+func EncodeBlock(words [16]uint32) []byte {
+	block := make([]byte, BlockSize)
+	for i, word := range words {
+		binary.LittleEndian.PutUint32(block[4*i:], word)
+	}
+	return block
+}
+
+// Pad - returns the MD4 padding of a message of length bytes
+func Pad(length uint64) []byte {
+	padding := []byte{0x80}
+	for (length+uint64(len(padding)))%BlockSize != BlockSize-8 {
+		padding = append(padding, 0)
+	}
+
+	bitLength := make([]byte, 8)
+	binary.LittleEndian.PutUint64(bitLength, length*8)
+	return append(padding, bitLength...)
+}
+
+// digest - MD4 hash.Hash implementation
+type digest struct {
+	state  [4]uint32
+	buffer []byte
+	length uint64
+}
+
+// New - returns a new MD4 hash.Hash
+func New() hash.Hash {
+	d := &digest{}
+	d.Reset()
+	return d
+}
+
+func (d *digest) Reset() {
+	d.state = InitialState
+	d.buffer = nil
+	d.length = 0
+}
+
+func (d *digest) Size() int {
+	return Size
+}
+
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
+
+func (d *digest) Write(data []byte) (int, error) {
+	d.length += uint64(len(data))
+	d.buffer = append(d.buffer, data...)
+
+	for len(d.buffer) >= BlockSize {
+		d.state = Compress(d.state, DecodeBlock(d.buffer[:BlockSize]))
+		d.buffer = d.buffer[BlockSize:]
+	}
+
+	return len(data), nil
+}
+
+func (d *digest) Sum(in []byte) []byte {
+	// work on a copy so the caller can keep writing
+	final := *d
+	final.buffer = append([]byte{}, d.buffer...)
+	final.Write(Pad(d.length))
+
+	digest := make([]byte, Size)
+	for i, word := range final.state {
+		binary.LittleEndian.PutUint32(digest[4*i:], word)
+	}
+	return append(in, digest...)
+}
+
+// Sum - returns the MD4 digest of data
+func Sum(data []byte) [Size]byte {
+	var sum [Size]byte
+	d := New()
+	d.Write(data)
+	copy(sum[:], d.Sum(nil))
+	return sum
+}
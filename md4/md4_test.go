@@ -0,0 +1,29 @@
+package md4
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestSum(t *testing.T) {
+	vectors := []struct {
+		message string
+		digest  string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"abc", "a448017aaf21d8525fc10ae87aa6729d"},
+		{"message digest", "d9130a8164549fe818874806e1c7014b"},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890",
+			"e33b4ddc9c38f2199c3e7b164fcc0536"},
+	}
+
+	for _, vector := range vectors {
+		// when
+		digest := Sum([]byte(vector.message))
+
+		// then
+		if hex.EncodeToString(digest[:]) != vector.digest {
+			t.Errorf("Sum(%q) = %x, expected %s", vector.message, digest, vector.digest)
+		}
+	}
+}
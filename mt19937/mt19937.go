@@ -0,0 +1,65 @@
+package mt19937
+
+const (
+	n = 624
+	m = 397
+
+	matrixA   = 0x9908b0df
+	upperMask = 0x80000000
+	lowerMask = 0x7fffffff
+)
+
+// MT19937 - 32 bits Mersenne Twister pseudo random number generator
+type MT19937 struct {
+	state [n]uint32
+	index int
+}
+
+// New - returns a generator seeded with seed
+func New(seed uint32) *MT19937 {
+	mt := &MT19937{}
+	mt.Seed(seed)
+	return mt
+}
+
+// Seed - reinitializes the generator state from seed
+func (mt *MT19937) Seed(seed uint32) {
+	mt.state[0] = seed
+	for i := 1; i < n; i++ {
+		mt.state[i] = 1812433253*(mt.state[i-1]^mt.state[i-1]>>30) + uint32(i)
+	}
+	mt.index = n
+}
+
+// twist - generates the next n state words
+func (mt *MT19937) twist() {
+	for i := 0; i < n; i++ {
+		y := mt.state[i]&upperMask | mt.state[(i+1)%n]&lowerMask
+		next := mt.state[(i+m)%n] ^ y>>1
+		if y&1 != 0 {
+			next ^= matrixA
+		}
+		mt.state[i] = next
+	}
+	mt.index = 0
+}
+
+// Temper - applies the MT19937 output tempering transform to a state word
+func Temper(y uint32) uint32 {
+	y ^= y >> 11
+	y ^= y << 7 & 0x9d2c5680
+	y ^= y << 15 & 0xefc60000
+	y ^= y >> 18
+	return y
+}
+
+// Uint32 - returns the next pseudo random 32 bits output
+func (mt *MT19937) Uint32() uint32 {
+	if mt.index >= n {
+		mt.twist()
+	}
+
+	y := mt.state[mt.index]
+	mt.index++
+	return Temper(y)
+}
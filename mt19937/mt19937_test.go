@@ -0,0 +1,23 @@
+package mt19937
+
+import "testing"
+
+func TestUint32(t *testing.T) {
+	// given
+	mt := New(5489)
+
+	// when
+	first := mt.Uint32()
+	for i := 2; i < 10000; i++ {
+		mt.Uint32()
+	}
+	tenThousandth := mt.Uint32()
+
+	// then
+	if first != 3499211612 {
+		t.Errorf("Uint32() = %d, expected 3499211612", first)
+	}
+	if tenThousandth != 4123659995 {
+		t.Errorf("10000th Uint32() = %d, expected 4123659995", tenThousandth)
+	}
+}
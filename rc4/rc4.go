@@ -0,0 +1,46 @@
+package rc4
+
+import "errors"
+
+// Cipher - RC4 stream cipher instance
+type Cipher struct {
+	s    [256]byte
+	i, j byte
+}
+
+// NewCipher - returns a new RC4 cipher keyed with key (1 to 256 bytes)
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) < 1 || len(key) > 256 {
+		return nil, errors.New("invalid RC4 key size")
+	}
+
+	c := &Cipher{}
+	for i := range c.s {
+		c.s[i] = byte(i)
+	}
+
+	var j byte
+	for i, k := 0, 0; i < len(c.s); i, k = i+1, k+1 {
+		// cycle through the key without a costly modulo
+		if k == len(key) {
+			k = 0
+		}
+		j += c.s[i] + key[k]
+		c.s[i], c.s[j] = c.s[j], c.s[i]
+	}
+
+	return c, nil
+}
+
+// XORKeyStream - XORs src with the next keystream bytes into dst, which
+// must be at least as long as src and may be the same slice
+func (c *Cipher) XORKeyStream(dst, src []byte) {
+	i, j := c.i, c.j
+	for k, b := range src {
+		i++
+		j += c.s[i]
+		c.s[i], c.s[j] = c.s[j], c.s[i]
+		dst[k] = b ^ c.s[c.s[i]+c.s[j]]
+	}
+	c.i, c.j = i, j
+}
@@ -0,0 +1,35 @@
+package rc4
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestXORKeyStream(t *testing.T) {
+	vectors := []struct {
+		key        string
+		plaintext  string
+		ciphertext string
+	}{
+		{"Key", "Plaintext", "bbf316e8d940af0ad3"},
+		{"Wiki", "pedia", "1021bf0420"},
+		{"Secret", "Attack at dawn", "45a01f645fc35b383552544b9bf5"},
+	}
+
+	for _, vector := range vectors {
+		// given
+		c, err := NewCipher([]byte(vector.key))
+		if err != nil {
+			t.Fatalf("Error creating cipher: %s", err.Error())
+		}
+		ciphertext := make([]byte, len(vector.plaintext))
+
+		// when
+		c.XORKeyStream(ciphertext, []byte(vector.plaintext))
+
+		// then
+		if hex.EncodeToString(ciphertext) != vector.ciphertext {
+			t.Errorf("XORKeyStream(%q) = %x, expected %s", vector.plaintext, ciphertext, vector.ciphertext)
+		}
+	}
+}
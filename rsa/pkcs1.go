@@ -0,0 +1,136 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// IntToBytes - returns the big-endian representation of x left padded with
+// zeros up to size bytes
+func IntToBytes(x *big.Int, size int) []byte {
+	data := x.Bytes()
+	if len(data) >= size {
+		return data
+	}
+
+	padded := make([]byte, size)
+	copy(padded[size-len(data):], data)
+	return padded
+}
+
+// PadPKCS1v15Encryption - encodes message into a k bytes PKCS#1 v1.5
+// encryption block, 00 02 || PS || 00 || message, with PS being at least
+// 8 random non zero bytes
+func PadPKCS1v15Encryption(message []byte, k int) ([]byte, error) {
+	if len(message) > k-11 {
+		return nil, errors.New("message too long")
+	}
+
+	padded := make([]byte, k)
+	padded[1] = 2
+
+	ps := padded[2 : k-len(message)-1]
+	if _, err := rand.Read(ps); err != nil {
+		return nil, err
+	}
+	for i := range ps {
+		for ps[i] == 0 {
+			if _, err := rand.Read(ps[i : i+1]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	copy(padded[k-len(message):], message)
+	return padded, nil
+}
+
+// UnpadPKCS1v15Encryption - returns the message within a PKCS#1 v1.5 encryption block
+func UnpadPKCS1v15Encryption(padded []byte) ([]byte, error) {
+	if len(padded) < 11 || padded[0] != 0 || padded[1] != 2 {
+		return nil, errors.New("invalid PKCS#1 v1.5 padding")
+	}
+
+	for i := 2; i < len(padded); i++ {
+		if padded[i] == 0 {
+			if i < 10 {
+				break // padding string shorter than 8 bytes
+			}
+			return padded[i+1:], nil
+		}
+	}
+
+	return nil, errors.New("invalid PKCS#1 v1.5 padding")
+}
+
+// digestInfoPrefixes - ASN.1 DER encoding of the DigestInfo structure up
+// to the digest itself, for each supported hash function
+var digestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA1: {0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x05, 0x00,
+		0x04, 0x14},
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04,
+		0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+}
+
+// DigestInfo - returns the ASN.1 DigestInfo encoding of the hashed message
+func DigestInfo(hash crypto.Hash, hashed []byte) ([]byte, error) {
+	prefix, ok := digestInfoPrefixes[hash]
+	if !ok {
+		return nil, errors.New("unsupported hash function")
+	}
+	if len(hashed) != hash.Size() {
+		return nil, errors.New("invalid hash length")
+	}
+
+	return append(append([]byte{}, prefix...), hashed...), nil
+}
+
+// EncodePKCS1v15Signature - encodes the hashed message into a k bytes PKCS#1 v1.5
+// signature block, 00 01 || FF..FF || 00 || DigestInfo
+func EncodePKCS1v15Signature(hash crypto.Hash, hashed []byte, k int) ([]byte, error) {
+	digestInfo, err := DigestInfo(hash, hashed)
+	if err != nil {
+		return nil, err
+	}
+	if len(digestInfo) > k-11 {
+		return nil, errors.New("modulus too small for the hash function")
+	}
+
+	encoded := make([]byte, k)
+	encoded[1] = 1
+	for i := 2; i < k-len(digestInfo)-1; i++ {
+		encoded[i] = 0xff
+	}
+	copy(encoded[k-len(digestInfo):], digestInfo)
+
+	return encoded, nil
+}
+
+// SignPKCS1v15 - returns the PKCS#1 v1.5 signature of the hashed message
+func SignPKCS1v15(privateKey *PrivateKey, hash crypto.Hash, hashed []byte) (*big.Int, error) {
+	encoded, err := EncodePKCS1v15Signature(hash, hashed, privateKey.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	return Decrypt(privateKey, new(big.Int).SetBytes(encoded)), nil
+}
+
+// VerifyPKCS1v15 - verifies a PKCS#1 v1.5 signature of the hashed message,
+// the whole signature block must match the expected encoding
+func VerifyPKCS1v15(publicKey *PublicKey, hash crypto.Hash, hashed []byte, signature *big.Int) bool {
+	if signature.Cmp(publicKey.N) >= 0 {
+		return false
+	}
+
+	expected, err := EncodePKCS1v15Signature(hash, hashed, publicKey.Size())
+	if err != nil {
+		return false
+	}
+
+	encoded := IntToBytes(Encrypt(publicKey, signature), publicKey.Size())
+	return bytes.Equal(encoded, expected)
+}
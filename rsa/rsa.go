@@ -0,0 +1,119 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+var bigOne = big.NewInt(1)
+
+// PublicKey - textbook RSA public key
+type PublicKey struct {
+	N *big.Int
+	E *big.Int
+}
+
+// PrivateKey - textbook RSA private key
+type PrivateKey struct {
+	PublicKey
+	D *big.Int
+}
+
+// GenerateKey - generates a new key pair with a modulus of bits size and
+// public exponent e, new primes are picked until e is invertible mod et
+func GenerateKey(bits int, e int64) (*PrivateKey, error) {
+	publicExponent := big.NewInt(e)
+
+	for {
+		p, err := rand.Prime(rand.Reader, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(rand.Reader, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+
+		et := new(big.Int).Mul(new(big.Int).Sub(p, bigOne), new(big.Int).Sub(q, bigOne))
+		d, err := ModInverse(publicExponent, et)
+		if err != nil {
+			continue // e and et are not coprime, try other primes
+		}
+
+		return &PrivateKey{
+			PublicKey: PublicKey{N: new(big.Int).Mul(p, q), E: publicExponent},
+			D:         d,
+		}, nil
+	}
+}
+
+// Size - returns the modulus size in bytes
+func (pub *PublicKey) Size() int {
+	return (pub.N.BitLen() + 7) / 8
+}
+
+// Encrypt - raw RSA encryption, m^e mod n
+func Encrypt(pub *PublicKey, m *big.Int) *big.Int {
+	return new(big.Int).Exp(m, pub.E, pub.N)
+}
+
+// Decrypt - raw RSA decryption, c^d mod n
+func Decrypt(priv *PrivateKey, c *big.Int) *big.Int {
+	return new(big.Int).Exp(c, priv.D, priv.N)
+}
+
+// ExtendedGCD - returns g = gcd(a, b) and the Bezout coefficients x, y
+// such that a*x + b*y = g
+func ExtendedGCD(a, b *big.Int) (*big.Int, *big.Int, *big.Int) {
+	oldR, r := new(big.Int).Set(a), new(big.Int).Set(b)
+	oldX, x := big.NewInt(1), big.NewInt(0)
+	oldY, y := big.NewInt(0), big.NewInt(1)
+
+	quotient := new(big.Int)
+	for r.Sign() != 0 {
+		quotient.Div(oldR, r)
+
+		oldR, r = r, new(big.Int).Sub(oldR, new(big.Int).Mul(quotient, r))
+		oldX, x = x, new(big.Int).Sub(oldX, new(big.Int).Mul(quotient, x))
+		oldY, y = y, new(big.Int).Sub(oldY, new(big.Int).Mul(quotient, y))
+	}
+
+	return oldR, oldX, oldY
+}
+
+// ModInverse - returns the inverse of a modulo n
+func ModInverse(a, n *big.Int) (*big.Int, error) {
+	g, x, _ := ExtendedGCD(new(big.Int).Mod(a, n), n)
+	if g.Cmp(bigOne) != 0 {
+		return nil, errors.New("value is not invertible")
+	}
+
+	return x.Mod(x, n), nil
+}
+
+// CubeRoot - returns the integer cube root of n rounded down
+func CubeRoot(n *big.Int) *big.Int {
+	if n.Sign() <= 0 {
+		return new(big.Int)
+	}
+
+	// start from a power of 2 above the root and go
+	// down with Newton iterations until it stops decreasing
+	x := new(big.Int).Lsh(bigOne, uint(n.BitLen()+2)/3)
+	three := big.NewInt(3)
+	for {
+		y := new(big.Int).Mul(x, x)
+		y.Div(n, y)
+		y.Add(y, new(big.Int).Lsh(x, 1))
+		y.Div(y, three)
+
+		if y.Cmp(x) >= 0 {
+			return x
+		}
+		x = y
+	}
+}
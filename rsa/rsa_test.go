@@ -0,0 +1,108 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestModInverse(t *testing.T) {
+	// given
+	a, n := big.NewInt(17), big.NewInt(3120)
+	expectedInverse := big.NewInt(2753)
+
+	// when
+	inverse, err := ModInverse(a, n)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	// then
+	if inverse.Cmp(expectedInverse) != 0 {
+		t.Errorf("ModInverse(%s, %s) = %s, expected %s", a, n, inverse, expectedInverse)
+	}
+}
+
+func TestCubeRoot(t *testing.T) {
+	for _, root := range []int64{1, 2, 3, 10, 1234567, 9876543210} {
+		// given
+		r := big.NewInt(root)
+		cube := new(big.Int).Exp(r, big.NewInt(3), nil)
+
+		// when
+		exact := CubeRoot(cube)
+		rounded := CubeRoot(new(big.Int).Add(cube, big.NewInt(1)))
+
+		// then
+		if exact.Cmp(r) != 0 || rounded.Cmp(r) != 0 {
+			t.Errorf("CubeRoot(%s) = %s, %s, expected %s", cube, exact, rounded, r)
+		}
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	// given
+	privateKey, err := GenerateKey(1024, 3)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	m := new(big.Int).SetBytes([]byte("YELLOW SUBMARINE"))
+
+	// when
+	c := Encrypt(&privateKey.PublicKey, m)
+	decrypted := Decrypt(privateKey, c)
+
+	// then
+	if decrypted.Cmp(m) != 0 {
+		t.Errorf("Decrypt(Encrypt(%x)) = %x", m, decrypted)
+	}
+}
+
+func TestPKCS1v15EncryptionPadding(t *testing.T) {
+	// given
+	message := []byte("kick it, CC")
+	k := 32
+
+	// when
+	padded, err := PadPKCS1v15Encryption(message, k)
+	if err != nil {
+		t.Fatalf("Error padding message: %s", err.Error())
+	}
+	unpadded, err := UnpadPKCS1v15Encryption(padded)
+	if err != nil {
+		t.Fatalf("Error unpadding message: %s", err.Error())
+	}
+
+	// then
+	if len(padded) != k || padded[0] != 0 || padded[1] != 2 {
+		t.Errorf("PadPKCS1v15Encryption(%s, %d) = %x, invalid block", message, k, padded)
+	}
+	if string(unpadded) != string(message) {
+		t.Errorf("UnpadPKCS1v15Encryption(%x) = %s, expected %s", padded, unpadded, message)
+	}
+}
+
+func TestSignVerifyPKCS1v15(t *testing.T) {
+	// given
+	privateKey, err := GenerateKey(1024, 3)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	hashed := sha256.Sum256([]byte("hi mom"))
+	otherHashed := sha256.Sum256([]byte("hi dad"))
+
+	// when
+	signature, err := SignPKCS1v15(privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatalf("Error signing: %s", err.Error())
+	}
+
+	// then
+	if !VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, hashed[:], signature) {
+		t.Errorf("VerifyPKCS1v15(...) rejected a valid signature")
+	}
+	if VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, otherHashed[:], signature) {
+		t.Errorf("VerifyPKCS1v15(...) accepted a signature for a different message")
+	}
+}
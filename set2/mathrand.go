@@ -0,0 +1,91 @@
+package cryptochallenges
+
+import "errors"
+
+const (
+	// RNGSourceLength - length of the math/rand rngSource lagged Fibonacci
+	// generator state
+	RNGSourceLength = 607
+
+	// lag of the second term of the recurrence
+	rngSourceTap = 273
+
+	int63Mask = 1<<63 - 1
+)
+
+// MathRandPredictor - model of the source returned by math/rand NewSource
+// (rngSource), an additive lagged Fibonacci generator whose outputs verify
+// x[t] = x[t-607] + x[t-273] mod 2^64, so knowing the last 607 Int63 outputs
+// (the 63 low bits of the state) predicts every following one. It only
+// applies to sources created with rand.NewSource(seed): since Go 1.20 the
+// global math/rand functions use a randomly seeded source, which is ChaCha8
+// since Go 1.22, and are not modelled
+type MathRandPredictor struct {
+	// circular buffer holding the last RNGSourceLength outputs
+	outputs [RNGSourceLength]int64
+	next    int
+}
+
+// NewMathRandPredictor - returns a predictor from at least RNGSourceLength
+// consecutive Int63 outputs of a seeded math/rand source
+func NewMathRandPredictor(outputs []int64) (*MathRandPredictor, error) {
+	if len(outputs) < RNGSourceLength {
+		return nil, errors.New("not enough outputs to recover the state")
+	}
+
+	p := &MathRandPredictor{}
+	copy(p.outputs[:], outputs[len(outputs)-RNGSourceLength:])
+	return p, nil
+}
+
+// Int63 - returns the next Int63 output of the source
+func (p *MathRandPredictor) Int63() int64 {
+	tap := (p.next + RNGSourceLength - rngSourceTap) % RNGSourceLength
+	x := (p.outputs[p.next] + p.outputs[tap]) & int63Mask
+
+	p.outputs[p.next] = x
+	p.next = (p.next + 1) % RNGSourceLength
+	return x
+}
+
+// Int31 - returns the next Int31 output of the source, as rand.Rand does
+func (p *MathRandPredictor) Int31() int32 {
+	return int32(p.Int63() >> 32)
+}
+
+// Intn - returns the next Intn(n) output, for 0 < n < 2^31, replicating the
+// rand.Rand rejection sampling
+func (p *MathRandPredictor) Intn(n int) int {
+	n32 := int32(n)
+	if n32&(n32-1) == 0 {
+		return int(p.Int31() & (n32 - 1))
+	}
+
+	max := int32((1 << 31) - 1 - (1<<31)%uint32(n32))
+	v := p.Int31()
+	for v > max {
+		v = p.Int31()
+	}
+	return int(v % n32)
+}
+
+// RandomEncryptionPrediction - decisions taken by RandomEncryptionOracle
+type RandomEncryptionPrediction struct {
+	ECB        bool
+	PrefixSize int
+	SuffixSize int
+}
+
+// PredictRandomEncryptionOracle - predicts the decisions of the next
+// RandomEncryptionOracleWithRand call drawing from the modelled seeded
+// source, consuming the matching source outputs
+func (p *MathRandPredictor) PredictRandomEncryptionOracle() RandomEncryptionPrediction {
+	// same order of calls as randomizePlaintext and RandomEncryptionOracle
+	prefixSize := p.Intn(10-5) + 5
+	suffixSize := p.Intn(10-5) + 5
+	return RandomEncryptionPrediction{
+		ECB:        p.Intn(2) == 0,
+		PrefixSize: prefixSize,
+		SuffixSize: suffixSize,
+	}
+}
@@ -32,6 +32,17 @@ type profileOracle func(email string) ([]byte, error)
 // DecryptCBC decrypts a ciphertext previously encrypted in CBC mode using the
 // input block cipher
 func DecryptCBC(ciphertext, iv []byte, blockCipher cipher.Block, blockSize int) ([]byte, error) {
+	paddedPlaintext, err := DecryptCBCBlocks(ciphertext, iv, blockCipher, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return tools.RemovePkcs7Padding(paddedPlaintext), nil
+}
+
+// DecryptCBCBlocks decrypts a ciphertext previously encrypted in CBC mode
+// using the input block cipher, without removing the padding
+func DecryptCBCBlocks(ciphertext, iv []byte, blockCipher cipher.Block, blockSize int) ([]byte, error) {
 	if len(iv) != blockSize {
 		return nil, errors.New("invalid IV size")
 	}
@@ -59,7 +70,7 @@ func DecryptCBC(ciphertext, iv []byte, blockCipher cipher.Block, blockSize int)
 		previousBlock = ciphertextBlock
 	}
 
-	return tools.RemovePkcs7Padding(paddedPlaintext), nil
+	return paddedPlaintext, nil
 }
 
 // EncryptCBC encrypts in CBC mode using the input block cipher
@@ -70,6 +81,21 @@ func EncryptCBC(plaintext []byte, blockCipher cipher.Block, blockSize int) ([]by
 		return nil, nil, err
 	}
 
+	ciphertext, err := EncryptCBCWithIV(plaintext, iv, blockCipher, blockSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return iv, ciphertext, nil
+}
+
+// EncryptCBCWithIV encrypts in CBC mode using the input block cipher and
+// the given IV instead of a random one
+func EncryptCBCWithIV(plaintext, iv []byte, blockCipher cipher.Block, blockSize int) ([]byte, error) {
+	if len(iv) != blockSize {
+		return nil, errors.New("invalid IV size")
+	}
+
 	paddedPlaintext := tools.ApplyPkcs7Padding(plaintext, blockSize)
 	ciphertext := make([]byte, len(paddedPlaintext))
 
@@ -82,7 +108,7 @@ func EncryptCBC(plaintext []byte, blockCipher cipher.Block, blockSize int) ([]by
 
 		xoredData, err := cryptochallenges.Xor(previousCiphertextBlock, paddedPlaintext[blockStart:blockEnd])
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		ciphertextBlock := make([]byte, blockSize)
@@ -92,7 +118,7 @@ func EncryptCBC(plaintext []byte, blockCipher cipher.Block, blockSize int) ([]by
 		previousCiphertextBlock = ciphertextBlock
 	}
 
-	return iv, ciphertext, nil
+	return ciphertext, nil
 }
 
 func generateIV(blockSize int) ([]byte, error) {
@@ -107,10 +133,30 @@ func generateKey(keySize int) ([]byte, error) {
 	return key, err
 }
 
+// intnSource - source of the random encryption oracle decisions
+type intnSource interface {
+	Intn(n int) int
+}
+
+// globalRand - intnSource backed by the global math/rand functions
+type globalRand struct{}
+
+func (globalRand) Intn(n int) int { return random.Intn(n) }
+
 // RandomEncryptionOracle - encrypts the given plaintext after prepending and appending some random text to it
 // encryption on average 50% of the time using AES in ECB mode and 50% of the time using AES in CBC mode
 func RandomEncryptionOracle(plaintext []byte) ([]byte, error) {
-	plaintext, err := randomizePlaintext(plaintext)
+	return randomEncryptionOracle(plaintext, globalRand{})
+}
+
+// RandomEncryptionOracleWithRand - same as RandomEncryptionOracle, taking the
+// ECB/CBC and random data sizes decisions from rng
+func RandomEncryptionOracleWithRand(plaintext []byte, rng *random.Rand) ([]byte, error) {
+	return randomEncryptionOracle(plaintext, rng)
+}
+
+func randomEncryptionOracle(plaintext []byte, rng intnSource) ([]byte, error) {
+	plaintext, err := randomizePlaintext(plaintext, rng)
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +169,7 @@ func RandomEncryptionOracle(plaintext []byte) ([]byte, error) {
 
 	var ciphertext []byte
 
-	if random.Intn(2) == 0 {
+	if rng.Intn(2) == 0 {
 		// encrypt ecb
 		ciphertext, err = cryptochallenges.EncryptAESinECB(plaintext, key)
 	} else {
@@ -137,9 +183,9 @@ func RandomEncryptionOracle(plaintext []byte) ([]byte, error) {
 
 // randomizePlaintext adds 5-10 bytes of random data
 // before and after the  input plaintext
-func randomizePlaintext(plaintext []byte) ([]byte, error) {
-	beforeDataSize := random.Intn(10-5) + 5
-	afterDataSize := random.Intn(10-5) + 5
+func randomizePlaintext(plaintext []byte, rng intnSource) ([]byte, error) {
+	beforeDataSize := rng.Intn(10-5) + 5
+	afterDataSize := rng.Intn(10-5) + 5
 
 	beforeData := make([]byte, beforeDataSize)
 	afterData := make([]byte, afterDataSize)
@@ -5,6 +5,7 @@ import (
 	"crypto/aes"
 	"encoding/base64"
 	"math"
+	random "math/rand"
 	"testing"
 
 	cryptochallenges "github.com/ka3de/go-cryptochallenges/set1"
@@ -153,3 +154,62 @@ func TestBreakECBwithCutAndPaste(t *testing.T) {
 		t.Errorf("Error, expected role 'admin', got '%s'", adminProfile.Role)
 	}
 }
+
+func TestMathRandPredictor(t *testing.T) {
+	// given
+	source := random.New(random.NewSource(1337))
+	outputs := make([]int64, RNGSourceLength+100)
+	for i := range outputs {
+		outputs[i] = source.Int63()
+	}
+
+	// when
+	predictor, err := NewMathRandPredictor(outputs)
+	if err != nil {
+		t.Fatalf("Error creating predictor: %s", err.Error())
+	}
+
+	// then
+	for i := 0; i < 2000; i++ {
+		n := []int{2, 5, 7, 1 << 20, 1<<31 - 1}[i%5]
+		if predicted, actual := predictor.Intn(n), source.Intn(n); predicted != actual {
+			t.Fatalf("Intn(%d) #%d = %d, expected %d", n, i, predicted, actual)
+		}
+	}
+}
+
+func TestPredictRandomEncryptionOracle(t *testing.T) {
+	// given
+	// the oracle draws its decisions from a seeded source, after some of
+	// its outputs leaked
+	rng := random.New(random.NewSource(42))
+	outputs := make([]int64, RNGSourceLength)
+	for i := range outputs {
+		outputs[i] = rng.Int63()
+	}
+	predictor, err := NewMathRandPredictor(outputs)
+	if err != nil {
+		t.Fatalf("Error creating predictor: %s", err.Error())
+	}
+	plaintext := bytes.Repeat([]byte("A"), 4*cryptochallenges.AESBlockSize)
+
+	for i := 0; i < 50; i++ {
+		// when
+		prediction := predictor.PredictRandomEncryptionOracle()
+		ciphertext, err := RandomEncryptionOracleWithRand(plaintext, rng)
+		if err != nil {
+			t.Fatalf("Error encryption oracle: %s", err.Error())
+		}
+
+		// then
+		if isECB := IsECBEncrypted(ciphertext, cryptochallenges.AESBlockSize); isECB != prediction.ECB {
+			t.Errorf("PredictRandomEncryptionOracle() #%d ECB = %t, expected %t", i, prediction.ECB, isECB)
+		}
+		size := len(plaintext) + prediction.PrefixSize + prediction.SuffixSize
+		expectedSize := (size/cryptochallenges.AESBlockSize + 1) * cryptochallenges.AESBlockSize
+		if len(ciphertext) != expectedSize {
+			t.Errorf("PredictRandomEncryptionOracle() #%d predicts %d bytes ciphertext, got %d",
+				i, expectedSize, len(ciphertext))
+		}
+	}
+}
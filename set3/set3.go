@@ -0,0 +1,190 @@
+package cryptochallenges
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ka3de/go-cryptochallenges/mt19937"
+)
+
+// mt19937KeyStream - returns size keystream bytes, each generator output
+// providing 4 little-endian bytes
+func mt19937KeyStream(mt *mt19937.MT19937, size int) []byte {
+	keyStream := make([]byte, size+3)
+	for i := 0; i < size; i += 4 {
+		binary.LittleEndian.PutUint32(keyStream[i:], mt.Uint32())
+	}
+	return keyStream[:size]
+}
+
+// EncryptMT19937 - encrypts (or decrypts) data with the toy stream cipher
+// using the output of MT19937 seeded with the 16 bits key as keystream
+func EncryptMT19937(data []byte, key uint16) []byte {
+	keyStream := mt19937KeyStream(mt19937.New(uint32(key)), len(data))
+
+	output := make([]byte, len(data))
+	for i := range data {
+		output[i] = data[i] ^ keyStream[i]
+	}
+	return output
+}
+
+// randomInt - returns a random integer in [0, max)
+func randomInt(max int) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return 0, err
+	}
+	return int(n.Int64()), nil
+}
+
+// NewMT19937EncryptionOracle - returns an oracle encrypting 5 to 20 random
+// bytes followed by the plaintext with the MT19937 stream cipher under a
+// random key, and the key itself
+func NewMT19937EncryptionOracle() (func(plaintext []byte) ([]byte, error), uint16, error) {
+	randomKey, err := randomInt(1 << 16)
+	if err != nil {
+		return nil, 0, err
+	}
+	key := uint16(randomKey)
+
+	oracle := func(plaintext []byte) ([]byte, error) {
+		prefixSize, err := randomInt(16)
+		if err != nil {
+			return nil, err
+		}
+
+		prefix := make([]byte, 5+prefixSize)
+		if _, err := rand.Read(prefix); err != nil {
+			return nil, err
+		}
+
+		return EncryptMT19937(append(prefix, plaintext...), key), nil
+	}
+
+	return oracle, key, nil
+}
+
+// BreakMT19937StreamCipher - recovers the 16 bits key of a ciphertext whose
+// plaintext ends with knownSuffix by trying every key
+func BreakMT19937StreamCipher(ciphertext, knownSuffix []byte) (uint16, error) {
+	if len(knownSuffix) == 0 || len(knownSuffix) > len(ciphertext) {
+		return 0, errors.New("invalid known suffix")
+	}
+
+	for key := 0; key < 1<<16; key++ {
+		if bytes.HasSuffix(EncryptMT19937(ciphertext, uint16(key)), knownSuffix) {
+			return uint16(key), nil
+		}
+	}
+
+	return 0, errors.New("key not found")
+}
+
+// PasswordResetTokenSize - size in bytes of the password reset tokens
+const PasswordResetTokenSize = 16
+
+// NewPasswordResetToken - returns a password reset token made of the first
+// MT19937 outputs, seeded with the Unix time now
+func NewPasswordResetToken(now time.Time) []byte {
+	return mt19937KeyStream(mt19937.New(uint32(now.Unix())), PasswordResetTokenSize)
+}
+
+// IsTimeSeededToken - returns whether token was generated by MT19937 seeded
+// with a Unix time in the window before now
+func IsTimeSeededToken(token []byte, now time.Time, window time.Duration) bool {
+	if len(token) == 0 {
+		return false
+	}
+
+	for seed := now.Add(-window).Unix(); seed <= now.Unix(); seed++ {
+		mt := mt19937.New(uint32(seed))
+		if bytes.Equal(mt19937KeyStream(mt, len(token)), token) {
+			return true
+		}
+	}
+	return false
+}
+
+// SeedablePRNG - pseudo random number generator seeded with 32 bits
+type SeedablePRNG interface {
+	Seed(seed uint32)
+	Uint32() uint32
+}
+
+// Clock - time source, injectable so waits can be simulated
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time        { return time.Now() }
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// SystemClock - clock backed by the system time
+var SystemClock Clock = systemClock{}
+
+// SimulatedClock - clock whose sleeps advance its time instantly
+type SimulatedClock struct {
+	now time.Time
+}
+
+// NewSimulatedClock - returns a simulated clock starting at start
+func NewSimulatedClock(start time.Time) *SimulatedClock {
+	return &SimulatedClock{now: start}
+}
+
+// Now - returns the simulated current time
+func (c *SimulatedClock) Now() time.Time {
+	return c.now
+}
+
+// Sleep - advances the simulated time by d
+func (c *SimulatedClock) Sleep(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// randomWait - sleeps a random duration between 40 and 1000 seconds
+func randomWait(clock Clock) error {
+	seconds, err := randomInt(1000 - 40 + 1)
+	if err != nil {
+		return err
+	}
+	clock.Sleep(time.Duration(40+seconds) * time.Second)
+	return nil
+}
+
+// TimeSeededOutput - simulates a service that waits a random time, seeds prng
+// with the current Unix time, waits again and returns its first output
+func TimeSeededOutput(prng SeedablePRNG, clock Clock) (uint32, error) {
+	if err := randomWait(clock); err != nil {
+		return 0, err
+	}
+	prng.Seed(uint32(clock.Now().Unix()))
+
+	if err := randomWait(clock); err != nil {
+		return 0, err
+	}
+	return prng.Uint32(), nil
+}
+
+// CrackTimeSeed - recovers the Unix time seed of a prng first output by
+// trying every second in the window before the clock current time, most
+// recent first
+func CrackTimeSeed(output uint32, prng SeedablePRNG, clock Clock, window time.Duration) (uint32, error) {
+	now := clock.Now()
+	for seed := now.Unix(); seed >= now.Add(-window).Unix(); seed-- {
+		prng.Seed(uint32(seed))
+		if prng.Uint32() == output {
+			return uint32(seed), nil
+		}
+	}
+
+	return 0, errors.New("seed not found in the time window")
+}
@@ -0,0 +1,130 @@
+package cryptochallenges
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/ka3de/go-cryptochallenges/mt19937"
+)
+
+func TestEncryptMT19937(t *testing.T) {
+	// given
+	plaintext := []byte("Ice Ice Baby")
+
+	// when
+	ciphertext := EncryptMT19937(plaintext, 0xbeef)
+	decrypted := EncryptMT19937(ciphertext, 0xbeef)
+
+	// then
+	if bytes.Equal(ciphertext, plaintext) {
+		t.Errorf("EncryptMT19937(%q) did not modify the plaintext", plaintext)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("EncryptMT19937(EncryptMT19937(%q)) = %q, expected %q", plaintext, decrypted, plaintext)
+	}
+}
+
+func TestBreakMT19937StreamCipher(t *testing.T) {
+	// given
+	oracle, key, err := NewMT19937EncryptionOracle()
+	if err != nil {
+		t.Fatalf("Error creating oracle: %s", err.Error())
+	}
+	knownPlaintext := bytes.Repeat([]byte("A"), 14)
+	ciphertext, err := oracle(knownPlaintext)
+	if err != nil {
+		t.Fatalf("Error encrypting plaintext: %s", err.Error())
+	}
+
+	// when
+	recoveredKey, err := BreakMT19937StreamCipher(ciphertext, knownPlaintext)
+	if err != nil {
+		t.Fatalf("Error recovering key: %s", err.Error())
+	}
+
+	// then
+	if recoveredKey != key {
+		t.Errorf("BreakMT19937StreamCipher(...) = %d, expected %d", recoveredKey, key)
+	}
+}
+
+func TestIsTimeSeededToken(t *testing.T) {
+	// given
+	now := time.Unix(1700000000, 0)
+	timeToken := NewPasswordResetToken(now.Add(-90 * time.Second))
+	randomToken := make([]byte, PasswordResetTokenSize)
+	if _, err := rand.Read(randomToken); err != nil {
+		t.Fatalf("Error generating random token: %s", err.Error())
+	}
+
+	// then
+	if !IsTimeSeededToken(timeToken, now, 5*time.Minute) {
+		t.Errorf("IsTimeSeededToken(time seeded token) = false, expected true")
+	}
+	if IsTimeSeededToken(timeToken, now, time.Minute) {
+		t.Errorf("IsTimeSeededToken(time seeded token, out of window) = true, expected false")
+	}
+	if IsTimeSeededToken(randomToken, now, 5*time.Minute) {
+		t.Errorf("IsTimeSeededToken(random token) = true, expected false")
+	}
+}
+
+// lcg - minimal linear congruential generator implementing SeedablePRNG
+type lcg struct {
+	state uint32
+}
+
+func (g *lcg) Seed(seed uint32) {
+	g.state = seed
+}
+
+func (g *lcg) Uint32() uint32 {
+	g.state = 1664525*g.state + 1013904223
+	return g.state
+}
+
+// recordingClock - simulated clock remembering the times it handed out
+type recordingClock struct {
+	*SimulatedClock
+	readings []time.Time
+}
+
+func (c *recordingClock) Now() time.Time {
+	now := c.SimulatedClock.Now()
+	c.readings = append(c.readings, now)
+	return now
+}
+
+func TestCrackTimeSeed(t *testing.T) {
+	prngs := map[string]SeedablePRNG{
+		"MT19937": mt19937.New(0),
+		"LCG":     &lcg{},
+	}
+
+	for name, prng := range prngs {
+		// given
+		clock := &recordingClock{SimulatedClock: NewSimulatedClock(time.Unix(1700000000, 0))}
+		output, err := TimeSeededOutput(prng, clock)
+		if err != nil {
+			t.Fatalf("Error generating output: %s", err.Error())
+		}
+		// the service reads the clock once, to seed the prng
+		if len(clock.readings) != 1 {
+			t.Fatalf("TimeSeededOutput(...) read the clock %d times, expected 1", len(clock.readings))
+		}
+		expectedSeed := uint32(clock.readings[0].Unix())
+
+		// when
+		seed, err := CrackTimeSeed(output, prng, clock, 2000*time.Second)
+		if err != nil {
+			t.Fatalf("Error cracking %s seed: %s", name, err.Error())
+		}
+
+		// then
+		if seed != expectedSeed {
+			t.Errorf("CrackTimeSeed(%s) = %d, expected %d", name, seed, expectedSeed)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package cryptochallenges
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ka3de/go-cryptochallenges/tools"
+
+	set1 "github.com/ka3de/go-cryptochallenges/set1"
+	set2 "github.com/ka3de/go-cryptochallenges/set2"
+)
+
+// ctrKeyStreamAt - XORs src into dst with the CTR keystream starting at byte
+// offset: the counter block is the little-endian 64 bits nonce followed by
+// the little-endian 64 bits block counter
+func ctrKeyStreamAt(blockCipher cipher.Block, nonce uint64, dst, src []byte, offset int) {
+	counterBlock := make([]byte, aes.BlockSize)
+	keyStream := make([]byte, aes.BlockSize)
+	binary.LittleEndian.PutUint64(counterBlock, nonce)
+
+	for i := range src {
+		position := offset + i
+		// generate the keystream block when entering it (or at the start)
+		if i == 0 || position%aes.BlockSize == 0 {
+			binary.LittleEndian.PutUint64(counterBlock[8:], uint64(position/aes.BlockSize))
+			blockCipher.Encrypt(keyStream, counterBlock)
+		}
+		dst[i] = src[i] ^ keyStream[position%aes.BlockSize]
+	}
+}
+
+// EncryptCTR - encrypts (or decrypts) data with AES in CTR mode under key
+// and nonce
+func EncryptCTR(data, key []byte, nonce uint64) ([]byte, error) {
+	aesCipher, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]byte, len(data))
+	ctrKeyStreamAt(aesCipher, nonce, output, data, 0)
+	return output, nil
+}
+
+// CTRCiphertext - AES-CTR encrypted data supporting random access edits,
+// like a disk encryption API: any range can be re-encrypted in place
+// without touching the rest of the ciphertext
+type CTRCiphertext struct {
+	blockCipher cipher.Block
+	nonce       uint64
+	ciphertext  []byte
+}
+
+// NewCTRCiphertext - encrypts plaintext with AES-CTR under key and nonce
+func NewCTRCiphertext(plaintext, key []byte, nonce uint64) (*CTRCiphertext, error) {
+	aesCipher, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &CTRCiphertext{blockCipher: aesCipher, nonce: nonce, ciphertext: make([]byte, len(plaintext))}
+	ctrKeyStreamAt(aesCipher, nonce, c.ciphertext, plaintext, 0)
+	return c, nil
+}
+
+// Ciphertext - returns a copy of the current ciphertext
+func (c *CTRCiphertext) Ciphertext() []byte {
+	return append([]byte{}, c.ciphertext...)
+}
+
+// Len - returns the ciphertext length
+func (c *CTRCiphertext) Len() int {
+	return len(c.ciphertext)
+}
+
+// Read - decrypts length bytes starting at offset
+func (c *CTRCiphertext) Read(offset, length int) ([]byte, error) {
+	if offset < 0 || length < 0 || offset+length > len(c.ciphertext) {
+		return nil, errors.New("read out of range")
+	}
+
+	plaintext := make([]byte, length)
+	ctrKeyStreamAt(c.blockCipher, c.nonce, plaintext, c.ciphertext[offset:offset+length], offset)
+	return plaintext, nil
+}
+
+// Edit - seeks to offset and replaces the plaintext there with newPlaintext,
+// re-encrypting it in place. Edits past the end extend the ciphertext, as
+// long as they start within it
+func (c *CTRCiphertext) Edit(offset int, newPlaintext []byte) error {
+	if offset < 0 || offset > len(c.ciphertext) {
+		return errors.New("edit out of range")
+	}
+
+	if end := offset + len(newPlaintext); end > len(c.ciphertext) {
+		c.ciphertext = append(c.ciphertext, make([]byte, end-len(c.ciphertext))...)
+	}
+	ctrKeyStreamAt(c.blockCipher, c.nonce, c.ciphertext[offset:], newPlaintext, offset)
+	return nil
+}
+
+// CTRSeekAttack - recovers the whole plaintext of a CTR ciphertext exposing
+// the edit API: editing it with zeros leaks the keystream, which XORed with
+// the original ciphertext gives the plaintext
+func CTRSeekAttack(c *CTRCiphertext) ([]byte, error) {
+	ciphertext := c.Ciphertext()
+	if len(ciphertext) == 0 {
+		return []byte{}, nil
+	}
+
+	if err := c.Edit(0, make([]byte, len(ciphertext))); err != nil {
+		return nil, err
+	}
+	keyStream := c.Ciphertext()
+
+	plaintext, err := set1.Xor(ciphertext, keyStream)
+	if err != nil {
+		return nil, err
+	}
+
+	// put the original contents back
+	if err := c.Edit(0, plaintext); err != nil {
+		return nil, err
+	}
+
+	return plaintext, nil
+}
+
+// CommentOracle - encrypts user data within a comment string with AES-CTR
+// under a fixed random key and nonce
+type CommentOracle struct {
+	key   []byte
+	nonce uint64
+}
+
+// NewCommentOracle - returns a comment oracle with a random key and nonce
+func NewCommentOracle() (*CommentOracle, error) {
+	secret := make([]byte, aes.BlockSize+8)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return &CommentOracle{key: secret[:aes.BlockSize], nonce: binary.LittleEndian.Uint64(secret[aes.BlockSize:])}, nil
+}
+
+// NewComment - returns the comment string for userData
+func NewComment(userData string) string {
+	// strip ';' and '=' characters
+	userData = strings.Replace(userData, ";", "", -1)
+	userData = strings.Replace(userData, "=", "", -1)
+	return "comment1=cooking%20MCs;userdata=" + userData + ";comment2=%20like%20a%20pound%20of%20bacon"
+}
+
+// Encrypt - returns the encrypted comment string for userData
+func (o *CommentOracle) Encrypt(userData string) ([]byte, error) {
+	return EncryptCTR([]byte(NewComment(userData)), o.key, o.nonce)
+}
+
+// IsAdmin - decrypts a comment string and returns whether it contains the
+// admin=true attribute
+func (o *CommentOracle) IsAdmin(ciphertext []byte) (bool, error) {
+	comment, err := EncryptCTR(ciphertext, o.key, o.nonce)
+	if err != nil {
+		return false, err
+	}
+
+	for _, attribute := range strings.Split(string(comment), ";") {
+		if attribute == "admin=true" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CTRBitFlippingAttack - returns a ciphertext the oracle considers admin:
+// the user data offset is found as the first ciphertext byte differing
+// between two encryptions, and ";admin=true;" is injected there by XORing
+// its difference with the known user data into the ciphertext
+func CTRBitFlippingAttack(oracle *CommentOracle) ([]byte, error) {
+	ciphertext1, err := oracle.Encrypt("A")
+	if err != nil {
+		return nil, err
+	}
+	ciphertext2, err := oracle.Encrypt("B")
+	if err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	for offset < len(ciphertext1) && ciphertext1[offset] == ciphertext2[offset] {
+		offset++
+	}
+	if offset == len(ciphertext1) {
+		return nil, errors.New("user data offset not found")
+	}
+
+	target := []byte(";admin=true;")
+	userData := bytes.Repeat([]byte("A"), len(target))
+	ciphertext, err := oracle.Encrypt(string(userData))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range target {
+		ciphertext[offset+i] ^= userData[i] ^ target[i]
+	}
+	return ciphertext, nil
+}
+
+// EncryptCBCKeyAsIV - encrypts plaintext with AES-CBC using the key as IV
+func EncryptCBCKeyAsIV(plaintext, key []byte) ([]byte, error) {
+	aesCipher, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return set2.EncryptCBCWithIV(plaintext, key, aesCipher, aes.BlockSize)
+}
+
+// decryptCBCKeyAsIVBlocks - decrypts ciphertext with AES-CBC using the key as
+// IV, without removing the padding
+func decryptCBCKeyAsIVBlocks(ciphertext, key []byte) ([]byte, error) {
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid ciphertext length")
+	}
+
+	aesCipher, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return set2.DecryptCBCBlocks(ciphertext, key, aesCipher, aes.BlockSize)
+}
+
+// DecryptCBCKeyAsIV - decrypts a ciphertext encrypted with AES-CBC using the
+// key as IV
+func DecryptCBCKeyAsIV(ciphertext, key []byte) ([]byte, error) {
+	plaintext, err := decryptCBCKeyAsIVBlocks(ciphertext, key)
+	if err != nil {
+		return nil, err
+	}
+	return tools.RemoveValidPkcs7Padding(plaintext, aes.BlockSize)
+}
+
+// HighASCIIError - error returned by the receiver when the decrypted message
+// is not ASCII compliant, leaking the decrypted bytes
+type HighASCIIError struct {
+	Plaintext []byte
+}
+
+func (e *HighASCIIError) Error() string {
+	return fmt.Sprintf("invalid high-ASCII characters in message: %q", e.Plaintext)
+}
+
+// CBCKeyAsIVReceiver - receives messages encrypted with AES-CBC using its
+// random key as IV and verifies they are ASCII compliant
+type CBCKeyAsIVReceiver struct {
+	key []byte
+}
+
+// NewCBCKeyAsIVReceiver - returns a receiver with a random key
+func NewCBCKeyAsIVReceiver() (*CBCKeyAsIVReceiver, error) {
+	key := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return &CBCKeyAsIVReceiver{key: key}, nil
+}
+
+// Encrypt - returns the encryption of plaintext as the sender would send it
+func (r *CBCKeyAsIVReceiver) Encrypt(plaintext []byte) ([]byte, error) {
+	return EncryptCBCKeyAsIV(plaintext, r.key)
+}
+
+// Receive - decrypts ciphertext and returns a HighASCIIError holding the
+// decrypted bytes if any of them is high-ASCII
+func (r *CBCKeyAsIVReceiver) Receive(ciphertext []byte) error {
+	plaintext, err := decryptCBCKeyAsIVBlocks(ciphertext, r.key)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range plaintext {
+		if b >= 0x80 {
+			return &HighASCIIError{Plaintext: plaintext}
+		}
+	}
+
+	_, err = tools.RemoveValidPkcs7Padding(plaintext, aes.BlockSize)
+	return err
+}
+
+// CBCKeyAsIVAttack - recovers the receiver key from a ciphertext of at least
+// three blocks: the receiver decrypts C1 || 0 || C1 into P1' || P2' || P3'
+// with P1' = D(C1) ^ key and P3' = D(C1), so key = P1' ^ P3'
+func CBCKeyAsIVAttack(ciphertext []byte, receiver *CBCKeyAsIVReceiver) ([]byte, error) {
+	if len(ciphertext) < 3*aes.BlockSize {
+		return nil, errors.New("at least three ciphertext blocks are needed")
+	}
+
+	c1 := ciphertext[:aes.BlockSize]
+	modified := append(append(append([]byte{}, c1...), make([]byte, aes.BlockSize)...), c1...)
+
+	highASCIIError, ok := receiver.Receive(modified).(*HighASCIIError)
+	if !ok {
+		return nil, errors.New("receiver did not leak the decrypted message")
+	}
+
+	plaintext := highASCIIError.Plaintext
+	return set1.Xor(plaintext[:aes.BlockSize], plaintext[2*aes.BlockSize:3*aes.BlockSize])
+}
@@ -0,0 +1,196 @@
+package cryptochallenges
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/ka3de/go-cryptochallenges/tools"
+
+	set1 "github.com/ka3de/go-cryptochallenges/set1"
+)
+
+func readChallenge25Plaintext(t *testing.T) []byte {
+	b64Ciphertext, err := tools.ReadFileContent("../set1/7.txt")
+	if err != nil {
+		t.Fatalf("Error reading ciphertext file: %s", err.Error())
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(b64Ciphertext)
+	if err != nil {
+		t.Fatalf("Error decoding b64 ciphertext: %s", err.Error())
+	}
+
+	plaintext, err := set1.DecryptAESinECB(ciphertext, []byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatalf("Error decrypting ciphertext: %s", err.Error())
+	}
+	return plaintext
+}
+
+func randomKey(t *testing.T) []byte {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	return key
+}
+
+func TestEncryptCTR(t *testing.T) {
+	// given
+	ciphertext, _ := base64.StdEncoding.DecodeString(
+		"L77na/nrFsKvynd6HzOoG7GHTLXsTVu9qvY/2syLXzhPweyyMTJULu/6/kXX0KSvoOLSFQ==")
+	expectedPlaintext := "Yo, VIP Let's kick it Ice, Ice, baby Ice, Ice, baby "
+
+	// when
+	plaintext, err := EncryptCTR(ciphertext, []byte("YELLOW SUBMARINE"), 0)
+	if err != nil {
+		t.Fatalf("Error decrypting ciphertext: %s", err.Error())
+	}
+
+	// then
+	if string(plaintext) != expectedPlaintext {
+		t.Errorf("EncryptCTR(...) = %q, expected %q", plaintext, expectedPlaintext)
+	}
+}
+
+func TestCTRCiphertextEdit(t *testing.T) {
+	// given
+	c, err := NewCTRCiphertext([]byte("attack at dawn, bring the big guns"), randomKey(t), 42)
+	if err != nil {
+		t.Fatalf("Error encrypting plaintext: %s", err.Error())
+	}
+	before := c.Ciphertext()
+
+	// when
+	if err := c.Edit(10, []byte("dusk")); err != nil {
+		t.Fatalf("Error editing ciphertext: %s", err.Error())
+	}
+	plaintext, err := c.Read(0, c.Len())
+	if err != nil {
+		t.Fatalf("Error reading ciphertext: %s", err.Error())
+	}
+
+	// then
+	expectedPlaintext := "attack at dusk, bring the big guns"
+	if string(plaintext) != expectedPlaintext {
+		t.Errorf("Read(...) = %q, expected %q", plaintext, expectedPlaintext)
+	}
+	after := c.Ciphertext()
+	if !bytes.Equal(before[:10], after[:10]) || !bytes.Equal(before[14:], after[14:]) {
+		t.Errorf("Edit(10, ...) modified ciphertext outside of the edited range")
+	}
+}
+
+func TestCTRSeekAttack(t *testing.T) {
+	// given
+	plaintext := readChallenge25Plaintext(t)
+	c, err := NewCTRCiphertext(plaintext, randomKey(t), 0)
+	if err != nil {
+		t.Fatalf("Error encrypting plaintext: %s", err.Error())
+	}
+	ciphertext := c.Ciphertext()
+
+	// when
+	recovered, err := CTRSeekAttack(c)
+	if err != nil {
+		t.Fatalf("Error recovering plaintext: %s", err.Error())
+	}
+
+	// then
+	if !bytes.Equal(recovered, plaintext) {
+		t.Errorf("CTRSeekAttack(...) = %q, expected %q", recovered, plaintext)
+	}
+	if !bytes.Equal(c.Ciphertext(), ciphertext) {
+		t.Errorf("CTRSeekAttack(...) left the ciphertext modified")
+	}
+}
+
+func TestNewComment(t *testing.T) {
+	// when
+	comment := NewComment("foo;admin=true")
+
+	// then
+	expectedComment := "comment1=cooking%20MCs;userdata=fooadmintrue;comment2=%20like%20a%20pound%20of%20bacon"
+	if comment != expectedComment {
+		t.Errorf("NewComment(...) = %q, expected %q", comment, expectedComment)
+	}
+}
+
+func TestCTRBitFlippingAttack(t *testing.T) {
+	// given
+	oracle, err := NewCommentOracle()
+	if err != nil {
+		t.Fatalf("Error creating oracle: %s", err.Error())
+	}
+	ciphertext, err := oracle.Encrypt(";admin=true;")
+	if err != nil {
+		t.Fatalf("Error encrypting comment: %s", err.Error())
+	}
+	if isAdmin, _ := oracle.IsAdmin(ciphertext); isAdmin {
+		t.Fatalf("IsAdmin(...) = true for quoted user data, expected false")
+	}
+
+	// when
+	forged, err := CTRBitFlippingAttack(oracle)
+	if err != nil {
+		t.Fatalf("Error forging ciphertext: %s", err.Error())
+	}
+
+	// then
+	isAdmin, err := oracle.IsAdmin(forged)
+	if err != nil {
+		t.Fatalf("Error checking forged ciphertext: %s", err.Error())
+	}
+	if !isAdmin {
+		t.Errorf("IsAdmin(CTRBitFlippingAttack(...)) = false, expected true")
+	}
+}
+
+func TestEncryptAndDecryptCBCKeyAsIV(t *testing.T) {
+	// given
+	key := randomKey(t)
+	plaintext := []byte("We all live in a yellow submarine")
+
+	// when
+	ciphertext, err := EncryptCBCKeyAsIV(plaintext, key)
+	if err != nil {
+		t.Fatalf("Error encrypting plaintext: %s", err.Error())
+	}
+	decrypted, err := DecryptCBCKeyAsIV(ciphertext, key)
+	if err != nil {
+		t.Fatalf("Error decrypting ciphertext: %s", err.Error())
+	}
+
+	// then
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("DecryptCBCKeyAsIV(...) = %q, expected %q", decrypted, plaintext)
+	}
+}
+
+func TestCBCKeyAsIVAttack(t *testing.T) {
+	// given
+	receiver, err := NewCBCKeyAsIVReceiver()
+	if err != nil {
+		t.Fatalf("Error creating receiver: %s", err.Error())
+	}
+	ciphertext, err := receiver.Encrypt([]byte("comment1=cooking%20MCs;userdata=x;comment2=%20like%20a%20pound"))
+	if err != nil {
+		t.Fatalf("Error encrypting message: %s", err.Error())
+	}
+	if err := receiver.Receive(ciphertext); err != nil {
+		t.Fatalf("Error receiving legitimate message: %s", err.Error())
+	}
+
+	// when
+	key, err := CBCKeyAsIVAttack(ciphertext, receiver)
+	if err != nil {
+		t.Fatalf("Error recovering key: %s", err.Error())
+	}
+
+	// then
+	if !bytes.Equal(key, receiver.key) {
+		t.Errorf("CBCKeyAsIVAttack(...) = %x, expected %x", key, receiver.key)
+	}
+}
@@ -0,0 +1,788 @@
+package cryptochallenges
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"sync"
+
+	"github.com/ka3de/go-cryptochallenges/dh"
+	"github.com/ka3de/go-cryptochallenges/rsa"
+
+	set1 "github.com/ka3de/go-cryptochallenges/set1"
+	set2 "github.com/ka3de/go-cryptochallenges/set2"
+)
+
+// dhMessage - message exchanged between the parties of the DH protocol,
+// only the fields relevant to each protocol step are set
+type dhMessage struct {
+	P          *big.Int
+	G          *big.Int
+	PublicKey  *big.Int
+	IV         []byte
+	Ciphertext []byte
+}
+
+// dhConn - one endpoint of an in-process bidirectional DH channel
+type dhConn struct {
+	in  <-chan dhMessage
+	out chan<- dhMessage
+}
+
+// dhMITM - man in the middle sitting between Alice and Bob connections,
+// returns the plaintexts it has been able to recover
+type dhMITM func(alice, bob dhConn) ([][]byte, error)
+
+// newDHPipe - returns both endpoints of a new in-process DH channel
+func newDHPipe() (dhConn, dhConn) {
+	// protocol steps alternate, so a single slot per direction
+	// is enough to never block a sender whose peer is gone
+	aToB := make(chan dhMessage, 1)
+	bToA := make(chan dhMessage, 1)
+
+	return dhConn{in: bToA, out: aToB}, dhConn{in: aToB, out: bToA}
+}
+
+func (c dhConn) receive() (dhMessage, error) {
+	message, ok := <-c.in
+	if !ok {
+		return dhMessage{}, errors.New("connection closed by peer")
+	}
+	return message, nil
+}
+
+// dhCipher - returns the AES cipher keyed by the SHA-1 derived key of the shared secret
+func dhCipher(sharedSecret *big.Int) (cipher.Block, error) {
+	return aes.NewCipher(dh.DeriveKey(sharedSecret, set1.AESBlockSize))
+}
+
+// dhAlice - initiates the protocol proposing the (p, g) group, then sends every
+// message encrypted under the negotiated key and returns the replies echoed back
+func dhAlice(conn dhConn, p, g *big.Int, messages [][]byte) ([][]byte, error) {
+	defer close(conn.out)
+
+	conn.out <- dhMessage{P: p, G: g}
+	ack, err := conn.receive()
+	if err != nil {
+		return nil, err
+	}
+	p, g = ack.P, ack.G // use the group acknowledged by the peer
+
+	privateKey, publicKey, err := dh.GenerateKeyPair(p, g)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.out <- dhMessage{PublicKey: publicKey}
+	peerKey, err := conn.receive()
+	if err != nil {
+		return nil, err
+	}
+
+	blockCipher, err := dhCipher(dh.SharedSecret(peerKey.PublicKey, privateKey, p))
+	if err != nil {
+		return nil, err
+	}
+
+	var replies [][]byte
+	for _, message := range messages {
+		iv, ciphertext, err := set2.EncryptCBC(message, blockCipher, set1.AESBlockSize)
+		if err != nil {
+			return nil, err
+		}
+
+		conn.out <- dhMessage{IV: iv, Ciphertext: ciphertext}
+		reply, err := conn.receive()
+		if err != nil {
+			return nil, err
+		}
+
+		plaintext, err := set2.DecryptCBC(reply.Ciphertext, reply.IV, blockCipher, set1.AESBlockSize)
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, plaintext)
+	}
+
+	return replies, nil
+}
+
+// dhBob - acknowledges the group proposed by the peer and echoes back every
+// message it receives re-encrypted under a fresh IV
+func dhBob(conn dhConn) error {
+	defer close(conn.out)
+
+	group, err := conn.receive()
+	if err != nil {
+		return err
+	}
+	conn.out <- dhMessage{P: group.P, G: group.G}
+
+	peerKey, err := conn.receive()
+	if err != nil {
+		return err
+	}
+
+	privateKey, publicKey, err := dh.GenerateKeyPair(group.P, group.G)
+	if err != nil {
+		return err
+	}
+	conn.out <- dhMessage{PublicKey: publicKey}
+
+	blockCipher, err := dhCipher(dh.SharedSecret(peerKey.PublicKey, privateKey, group.P))
+	if err != nil {
+		return err
+	}
+
+	for message := range conn.in {
+		plaintext, err := set2.DecryptCBC(message.Ciphertext, message.IV, blockCipher, set1.AESBlockSize)
+		if err != nil {
+			return err
+		}
+
+		iv, ciphertext, err := set2.EncryptCBC(plaintext, blockCipher, set1.AESBlockSize)
+		if err != nil {
+			return err
+		}
+		conn.out <- dhMessage{IV: iv, Ciphertext: ciphertext}
+	}
+
+	return nil
+}
+
+// DHEchoProtocol - runs the DH echo protocol between Alice and Bob with no one
+// in the middle, returns the replies Alice got back from Bob
+func DHEchoProtocol(messages [][]byte) ([][]byte, error) {
+	aliceConn, bobConn := newDHPipe()
+
+	bobErr := make(chan error, 1)
+	go func() {
+		bobErr <- dhBob(bobConn)
+	}()
+
+	replies, err := dhAlice(aliceConn, dh.P, dh.G, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	return replies, <-bobErr
+}
+
+// runDHMITM - runs the DH echo protocol with the given attacker relaying
+// all traffic between Alice and Bob, returns the plaintexts it recovered
+func runDHMITM(messages [][]byte, mitm dhMITM) ([][]byte, error) {
+	aliceConn, mitmAliceConn := newDHPipe()
+	mitmBobConn, bobConn := newDHPipe()
+
+	aliceErr := make(chan error, 1)
+	bobErr := make(chan error, 1)
+	go func() {
+		_, err := dhAlice(aliceConn, dh.P, dh.G, messages)
+		aliceErr <- err
+	}()
+	go func() {
+		bobErr <- dhBob(bobConn)
+	}()
+
+	plaintexts, err := mitm(mitmAliceConn, mitmBobConn)
+	errAlice, errBob := <-aliceErr, <-bobErr
+
+	switch {
+	case err != nil:
+		return nil, err
+	case errAlice != nil:
+		return nil, errAlice
+	case errBob != nil:
+		return nil, errBob
+	}
+
+	return plaintexts, nil
+}
+
+// dhRelayMessages - forwards the encrypted messages from Alice to Bob and
+// the replies back, decrypting them with the shared secret forced by the attacker
+func dhRelayMessages(alice, bob dhConn, sharedSecret *big.Int) ([][]byte, error) {
+	blockCipher, err := dhCipher(sharedSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	var plaintexts [][]byte
+	for message := range alice.in {
+		plaintext, err := set2.DecryptCBC(message.Ciphertext, message.IV, blockCipher, set1.AESBlockSize)
+		if err != nil {
+			return nil, err
+		}
+		plaintexts = append(plaintexts, plaintext)
+
+		bob.out <- message
+		reply, err := bob.receive()
+		if err != nil {
+			return nil, err
+		}
+		alice.out <- reply
+	}
+
+	return plaintexts, nil
+}
+
+// dhKeyFixingMITM - replaces both public keys by p, which makes the
+// shared secret computed by both parties to be p^x mod p = 0
+func dhKeyFixingMITM(alice, bob dhConn) ([][]byte, error) {
+	defer close(alice.out)
+	defer close(bob.out)
+
+	group, err := alice.receive()
+	if err != nil {
+		return nil, err
+	}
+	bob.out <- group
+
+	ack, err := bob.receive()
+	if err != nil {
+		return nil, err
+	}
+	alice.out <- ack
+
+	if _, err = alice.receive(); err != nil {
+		return nil, err
+	}
+	bob.out <- dhMessage{PublicKey: group.P}
+
+	if _, err = bob.receive(); err != nil {
+		return nil, err
+	}
+	alice.out <- dhMessage{PublicKey: group.P}
+
+	return dhRelayMessages(alice, bob, big.NewInt(0))
+}
+
+// DHKeyFixingAttack - runs the DH echo protocol with a MITM that performs
+// a key-fixing attack, returns the plaintexts recovered by the attacker
+func DHKeyFixingAttack(messages [][]byte) ([][]byte, error) {
+	return runDHMITM(messages, dhKeyFixingMITM)
+}
+
+// newDHMaliciousGroupMITM - returns a MITM that replaces the negotiated group
+// generator by g, relaying the public keys untouched
+func newDHMaliciousGroupMITM(g *big.Int) dhMITM {
+	return func(alice, bob dhConn) ([][]byte, error) {
+		defer close(alice.out)
+		defer close(bob.out)
+
+		group, err := alice.receive()
+		if err != nil {
+			return nil, err
+		}
+		bob.out <- dhMessage{P: group.P, G: g}
+
+		ack, err := bob.receive()
+		if err != nil {
+			return nil, err
+		}
+		alice.out <- ack
+
+		alicePublicKey, err := alice.receive()
+		if err != nil {
+			return nil, err
+		}
+		bob.out <- alicePublicKey
+
+		bobPublicKey, err := bob.receive()
+		if err != nil {
+			return nil, err
+		}
+		alice.out <- bobPublicKey
+
+		sharedSecret, err := predictDHSharedSecret(group.P, g,
+			alicePublicKey.PublicKey, bobPublicKey.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return dhRelayMessages(alice, bob, sharedSecret)
+	}
+}
+
+// predictDHSharedSecret - returns the shared secret both parties end up with
+// once the group generator has been replaced by 1, p or p-1
+func predictDHSharedSecret(p, g, publicKeyA, publicKeyB *big.Int) (*big.Int, error) {
+	one := big.NewInt(1)
+	pMinusOne := new(big.Int).Sub(p, one)
+
+	switch {
+	case g.Cmp(one) == 0:
+		return one, nil
+	case new(big.Int).Mod(g, p).Sign() == 0:
+		return big.NewInt(0), nil
+	case g.Cmp(pMinusOne) == 0:
+		// (p-1)^(ab) is p-1 only when both private keys are odd,
+		// which is exactly when both public keys are p-1
+		if publicKeyA.Cmp(pMinusOne) == 0 && publicKeyB.Cmp(pMinusOne) == 0 {
+			return pMinusOne, nil
+		}
+		return one, nil
+	}
+
+	return nil, errors.New("unsupported malicious generator")
+}
+
+// DHMaliciousGroupAttack - runs the DH echo protocol with a MITM that replaces
+// the negotiated generator by g (1, p or p-1), returns the recovered plaintexts
+func DHMaliciousGroupAttack(messages [][]byte, g *big.Int) ([][]byte, error) {
+	return runDHMITM(messages, newDHMaliciousGroupMITM(g))
+}
+
+// srpK - SRP-6a multiplier parameter k = H(N || PAD(g)), g being left
+// padded with zeros to the length of N
+var srpK = srpHash(dh.P.Bytes(), dh.G.FillBytes(make([]byte, len(dh.P.Bytes()))))
+
+// srpMessage - message exchanged between the SRP client and server,
+// only the fields relevant to each protocol step are set
+type srpMessage struct {
+	Email      string   `json:"email,omitempty"`
+	PublicKey  *big.Int `json:"publicKey,omitempty"`
+	Salt       []byte   `json:"salt,omitempty"`
+	U          *big.Int `json:"u,omitempty"`
+	MAC        []byte   `json:"mac,omitempty"`
+	OK         bool     `json:"ok,omitempty"`
+	IV         []byte   `json:"iv,omitempty"`
+	Ciphertext []byte   `json:"ciphertext,omitempty"`
+}
+
+// srpUser - what the server stores for each registered user
+type srpUser struct {
+	salt     []byte
+	verifier *big.Int
+}
+
+// srpSessionState - per connection state needed by the server to check
+// the client MAC and seal the welcome message
+type srpSessionState struct {
+	salt       []byte
+	sessionKey []byte
+}
+
+// srpHash - SHA-256 of the concatenation of data as an integer
+func srpHash(data ...[]byte) *big.Int {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+// srpSessionKey - derives the session key K = SHA256(S)
+func srpSessionKey(sharedSecret *big.Int) []byte {
+	digest := sha256.Sum256(sharedSecret.Bytes())
+	return digest[:]
+}
+
+// srpMAC - HMAC-SHA256 of the salt under the session key, used by the
+// client to prove it knows the session key
+func srpMAC(sessionKey, salt []byte) []byte {
+	mac := hmac.New(sha256.New, sessionKey)
+	mac.Write(salt)
+	return mac.Sum(nil)
+}
+
+// srpPasswordVerifier - returns g^x mod N with x = SHA256(salt|password)
+func srpPasswordVerifier(salt []byte, password string) *big.Int {
+	x := srpHash(salt, []byte(password))
+	return new(big.Int).Exp(dh.G, x, dh.P)
+}
+
+// srpWelcomeMessage - message the server sends encrypted once the client is logged in
+func srpWelcomeMessage(email string) []byte {
+	return []byte("Welcome " + email)
+}
+
+// srpSealWelcome - encrypts the welcome message for email under the session key
+func srpSealWelcome(email string, sessionKey []byte) (srpMessage, error) {
+	aesCipher, err := aes.NewCipher(sessionKey[:set1.AESBlockSize])
+	if err != nil {
+		return srpMessage{}, err
+	}
+
+	iv, ciphertext, err := set2.EncryptCBC(srpWelcomeMessage(email), aesCipher, set1.AESBlockSize)
+	if err != nil {
+		return srpMessage{}, err
+	}
+
+	return srpMessage{OK: true, IV: iv, Ciphertext: ciphertext}, nil
+}
+
+// srpOpenWelcome - decrypts the welcome message received from the server
+func srpOpenWelcome(message srpMessage, sessionKey []byte) ([]byte, error) {
+	if !message.OK {
+		return nil, errors.New("login rejected by server")
+	}
+
+	aesCipher, err := aes.NewCipher(sessionKey[:set1.AESBlockSize])
+	if err != nil {
+		return nil, err
+	}
+
+	return set2.DecryptCBC(message.Ciphertext, message.IV, aesCipher, set1.AESBlockSize)
+}
+
+// srpFinishLogin - sends the proof of the session key and returns the
+// decrypted welcome message
+func srpFinishLogin(encoder *json.Encoder, decoder *json.Decoder, sessionKey, salt []byte) ([]byte, error) {
+	if err := encoder.Encode(srpMessage{MAC: srpMAC(sessionKey, salt)}); err != nil {
+		return nil, err
+	}
+
+	var result srpMessage
+	if err := decoder.Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return srpOpenWelcome(result, sessionKey)
+}
+
+// srpCheckLogin - checks the MAC sent by the client and replies with either
+// the encrypted welcome message or a rejection
+func srpCheckLogin(encoder *json.Encoder, decoder *json.Decoder, email string, session srpSessionState) error {
+	var proof srpMessage
+	if err := decoder.Decode(&proof); err != nil {
+		return err
+	}
+
+	if !hmac.Equal(proof.MAC, srpMAC(session.sessionKey, session.salt)) {
+		if err := encoder.Encode(srpMessage{OK: false}); err != nil {
+			return err
+		}
+		return errors.New("invalid login for " + email)
+	}
+
+	welcome, err := srpSealWelcome(email, session.sessionKey)
+	if err != nil {
+		return err
+	}
+	return encoder.Encode(welcome)
+}
+
+// SRPServer - SRP-6a server over the NIST DH group
+type SRPServer struct {
+	mu    sync.RWMutex
+	users map[string]srpUser
+}
+
+// NewSRPServer - returns a new SRP server with no registered users
+func NewSRPServer() *SRPServer {
+	return &SRPServer{users: make(map[string]srpUser)}
+}
+
+// Register - registers a new user storing its salt and password verifier
+func (s *SRPServer) Register(email, password string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[email] = srpUser{salt: salt, verifier: srpPasswordVerifier(salt, password)}
+	return nil
+}
+
+// Serve - accepts connections on listener and serves each login in its own goroutine
+func (s *SRPServer) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn - handles a single login attempt on conn
+func (s *SRPServer) ServeConn(conn net.Conn) error {
+	defer conn.Close()
+	encoder, decoder := json.NewEncoder(conn), json.NewDecoder(conn)
+
+	var hello srpMessage
+	if err := decoder.Decode(&hello); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	user, ok := s.users[hello.Email]
+	s.mu.RUnlock()
+	if !ok || hello.PublicKey == nil {
+		encoder.Encode(srpMessage{OK: false})
+		return errors.New("unknown user " + hello.Email)
+	}
+
+	b, err := rand.Int(rand.Reader, dh.P)
+	if err != nil {
+		return err
+	}
+
+	// B = kv + g^b mod N
+	publicKey := new(big.Int).Exp(dh.G, b, dh.P)
+	publicKey.Add(publicKey, new(big.Int).Mul(srpK, user.verifier))
+	publicKey.Mod(publicKey, dh.P)
+
+	if err := encoder.Encode(srpMessage{Salt: user.salt, PublicKey: publicKey}); err != nil {
+		return err
+	}
+
+	// S = (A * v^u)^b mod N
+	u := srpHash(hello.PublicKey.Bytes(), publicKey.Bytes())
+	sharedSecret := new(big.Int).Exp(user.verifier, u, dh.P)
+	sharedSecret.Mul(sharedSecret, hello.PublicKey)
+	sharedSecret.Exp(sharedSecret, b, dh.P)
+
+	return srpCheckLogin(encoder, decoder, hello.Email,
+		srpSessionState{salt: user.salt, sessionKey: srpSessionKey(sharedSecret)})
+}
+
+// SRPLogin - logs in as email on the SRP server at the other side of conn,
+// returns the welcome message sent by the server
+func SRPLogin(conn net.Conn, email, password string) ([]byte, error) {
+	encoder, decoder := json.NewEncoder(conn), json.NewDecoder(conn)
+
+	a, publicKey, err := dh.GenerateKeyPair(dh.P, dh.G)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Encode(srpMessage{Email: email, PublicKey: publicKey}); err != nil {
+		return nil, err
+	}
+
+	var challenge srpMessage
+	if err := decoder.Decode(&challenge); err != nil {
+		return nil, err
+	}
+	if challenge.PublicKey == nil {
+		return nil, errors.New("login rejected by server")
+	}
+	// SRP-6a: the client aborts when B mod N == 0
+	if new(big.Int).Mod(challenge.PublicKey, dh.P).Sign() == 0 {
+		return nil, errors.New("invalid server public key")
+	}
+
+	// S = (B - k * g^x)^(a + u * x) mod N
+	u := srpHash(publicKey.Bytes(), challenge.PublicKey.Bytes())
+	x := srpHash(challenge.Salt, []byte(password))
+
+	base := new(big.Int).Exp(dh.G, x, dh.P)
+	base.Mul(base, srpK)
+	base.Sub(challenge.PublicKey, base)
+	base.Mod(base, dh.P)
+
+	exponent := new(big.Int).Mul(u, x)
+	exponent.Add(exponent, a)
+
+	sharedSecret := new(big.Int).Exp(base, exponent, dh.P)
+	return srpFinishLogin(encoder, decoder, srpSessionKey(sharedSecret), challenge.Salt)
+}
+
+// SRPZeroKeyLogin - logs in as email without knowing its password by sending
+// a public key A that is a multiple of N (0, N, 2N...), which forces the
+// server shared secret to be 0
+func SRPZeroKeyLogin(conn net.Conn, email string, publicKey *big.Int) ([]byte, error) {
+	encoder, decoder := json.NewEncoder(conn), json.NewDecoder(conn)
+
+	if err := encoder.Encode(srpMessage{Email: email, PublicKey: publicKey}); err != nil {
+		return nil, err
+	}
+
+	var challenge srpMessage
+	if err := decoder.Decode(&challenge); err != nil {
+		return nil, err
+	}
+
+	return srpFinishLogin(encoder, decoder, srpSessionKey(big.NewInt(0)), challenge.Salt)
+}
+
+// SimpleSRPServer - simplified SRP server, where B does not depend on the
+// password verifier and u is a random number instead of a hash of A and B
+type SimpleSRPServer struct {
+	mu    sync.RWMutex
+	users map[string]srpUser
+}
+
+// NewSimpleSRPServer - returns a new simplified SRP server with no registered users
+func NewSimpleSRPServer() *SimpleSRPServer {
+	return &SimpleSRPServer{users: make(map[string]srpUser)}
+}
+
+// Register - registers a new user storing its salt and password verifier
+func (s *SimpleSRPServer) Register(email, password string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[email] = srpUser{salt: salt, verifier: srpPasswordVerifier(salt, password)}
+	return nil
+}
+
+// ServeConn - handles a single login attempt on conn
+func (s *SimpleSRPServer) ServeConn(conn net.Conn) error {
+	defer conn.Close()
+	encoder, decoder := json.NewEncoder(conn), json.NewDecoder(conn)
+
+	var hello srpMessage
+	if err := decoder.Decode(&hello); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	user, ok := s.users[hello.Email]
+	s.mu.RUnlock()
+	if !ok || hello.PublicKey == nil {
+		encoder.Encode(srpMessage{OK: false})
+		return errors.New("unknown user " + hello.Email)
+	}
+
+	b, publicKey, err := dh.GenerateKeyPair(dh.P, dh.G)
+	if err != nil {
+		return err
+	}
+
+	u, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	if err := encoder.Encode(srpMessage{Salt: user.salt, PublicKey: publicKey, U: u}); err != nil {
+		return err
+	}
+
+	return srpCheckLogin(encoder, decoder, hello.Email, srpSessionState{
+		salt:       user.salt,
+		sessionKey: srpSessionKey(simpleSRPServerSecret(hello.PublicKey, user.verifier, u, b)),
+	})
+}
+
+// simpleSRPServerSecret - returns the server side shared secret S = (A * v^u)^b mod N
+func simpleSRPServerSecret(clientPublicKey, verifier, u, b *big.Int) *big.Int {
+	sharedSecret := new(big.Int).Exp(verifier, u, dh.P)
+	sharedSecret.Mul(sharedSecret, clientPublicKey)
+	return sharedSecret.Exp(sharedSecret, b, dh.P)
+}
+
+// SimpleSRPLogin - logs in as email on the simplified SRP server at the other
+// side of conn, returns the welcome message sent by the server
+func SimpleSRPLogin(conn net.Conn, email, password string) ([]byte, error) {
+	encoder, decoder := json.NewEncoder(conn), json.NewDecoder(conn)
+
+	a, publicKey, err := dh.GenerateKeyPair(dh.P, dh.G)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Encode(srpMessage{Email: email, PublicKey: publicKey}); err != nil {
+		return nil, err
+	}
+
+	var challenge srpMessage
+	if err := decoder.Decode(&challenge); err != nil {
+		return nil, err
+	}
+	if challenge.PublicKey == nil || challenge.U == nil {
+		return nil, errors.New("login rejected by server")
+	}
+
+	// S = B^(a + u * x) mod N
+	x := srpHash(challenge.Salt, []byte(password))
+	exponent := new(big.Int).Mul(challenge.U, x)
+	exponent.Add(exponent, a)
+
+	sharedSecret := new(big.Int).Exp(challenge.PublicKey, exponent, dh.P)
+	return srpFinishLogin(encoder, decoder, srpSessionKey(sharedSecret), challenge.Salt)
+}
+
+// SimpleSRPDictionaryAttack - impersonates a simplified SRP server on conn,
+// captures the client proof and runs an offline dictionary attack on it,
+// returns the client password
+func SimpleSRPDictionaryAttack(conn net.Conn, dictionary []string) (string, error) {
+	defer conn.Close()
+	encoder, decoder := json.NewEncoder(conn), json.NewDecoder(conn)
+
+	var hello srpMessage
+	if err := decoder.Decode(&hello); err != nil {
+		return "", err
+	}
+
+	// with b = 1 and u = 1 the client secret is S = A * g^x mod N,
+	// which only depends on values known by the attacker and the password
+	b, u := big.NewInt(1), big.NewInt(1)
+	salt := []byte{}
+	challenge := srpMessage{Salt: salt, PublicKey: new(big.Int).Exp(dh.G, b, dh.P), U: u}
+	if err := encoder.Encode(challenge); err != nil {
+		return "", err
+	}
+
+	var proof srpMessage
+	if err := decoder.Decode(&proof); err != nil {
+		return "", err
+	}
+
+	for _, password := range dictionary {
+		verifier := srpPasswordVerifier(salt, password)
+		sessionKey := srpSessionKey(simpleSRPServerSecret(hello.PublicKey, verifier, u, b))
+
+		if hmac.Equal(proof.MAC, srpMAC(sessionKey, salt)) {
+			return password, nil
+		}
+	}
+
+	return "", errors.New("password not found in dictionary")
+}
+
+// RSABroadcastAttack - recovers the message m encrypted under three different
+// e=3 public keys, combining the ciphertexts with the CRT into m^3 mod n0*n1*n2
+// and then taking its plain integer cube root
+func RSABroadcastAttack(ciphertexts []*big.Int, publicKeys []*rsa.PublicKey) (*big.Int, error) {
+	if len(ciphertexts) != 3 || len(publicKeys) != 3 {
+		return nil, errors.New("three ciphertexts and public keys are needed")
+	}
+
+	modulusProduct := big.NewInt(1)
+	for _, publicKey := range publicKeys {
+		if publicKey.E.Cmp(big.NewInt(3)) != 0 {
+			return nil, errors.New("public exponent must be 3")
+		}
+		modulusProduct.Mul(modulusProduct, publicKey.N)
+	}
+
+	result := new(big.Int)
+	for i, publicKey := range publicKeys {
+		// ms_i is the product of the other two moduli
+		ms := new(big.Int).Div(modulusProduct, publicKey.N)
+		msInverse, err := rsa.ModInverse(ms, publicKey.N)
+		if err != nil {
+			return nil, err
+		}
+
+		term := new(big.Int).Mul(ciphertexts[i], ms)
+		term.Mul(term, msInverse)
+		result.Add(result, term)
+	}
+	result.Mod(result, modulusProduct)
+
+	m := rsa.CubeRoot(result)
+	if new(big.Int).Exp(m, big.NewInt(3), nil).Cmp(result) != 0 {
+		return nil, errors.New("combined ciphertext is not a perfect cube")
+	}
+
+	return m, nil
+}
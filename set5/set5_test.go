@@ -0,0 +1,270 @@
+package cryptochallenges
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/ka3de/go-cryptochallenges/dh"
+	"github.com/ka3de/go-cryptochallenges/rsa"
+)
+
+var dhTestMessages = [][]byte{
+	[]byte("Hello Bob, it's Alice"),
+	[]byte("YELLOW SUBMARINE"),
+	[]byte("Cooking MC's like a pound of bacon"),
+}
+
+func equalMessages(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDHEchoProtocol(t *testing.T) {
+	// given
+
+	// when
+	replies, err := DHEchoProtocol(dhTestMessages)
+	if err != nil {
+		t.Fatalf("Error running DH echo protocol: %s", err.Error())
+	}
+
+	// then
+	if !equalMessages(replies, dhTestMessages) {
+		t.Errorf("DHEchoProtocol(...) = %q, expected %q", replies, dhTestMessages)
+	}
+}
+
+func TestDHKeyFixingAttack(t *testing.T) {
+	// given
+
+	// when
+	plaintexts, err := DHKeyFixingAttack(dhTestMessages)
+	if err != nil {
+		t.Fatalf("Error running DH key fixing attack: %s", err.Error())
+	}
+
+	// then
+	if !equalMessages(plaintexts, dhTestMessages) {
+		t.Errorf("DHKeyFixingAttack(...) = %q, expected %q", plaintexts, dhTestMessages)
+	}
+}
+
+func TestDHMaliciousGroupAttack(t *testing.T) {
+	// given
+	generators := []*big.Int{
+		big.NewInt(1),
+		dh.P,
+		new(big.Int).Sub(dh.P, big.NewInt(1)),
+	}
+
+	for _, g := range generators {
+		// p-1 yields a different shared secret depending on the keys parity,
+		// run it a few times to go through the different cases
+		for i := 0; i < 4; i++ {
+			// when
+			plaintexts, err := DHMaliciousGroupAttack(dhTestMessages, g)
+			if err != nil {
+				t.Fatalf("Error running DH malicious group attack: %s", err.Error())
+			}
+
+			// then
+			if !equalMessages(plaintexts, dhTestMessages) {
+				t.Errorf("DHMaliciousGroupAttack(..., %x) = %q, expected %q", g, plaintexts, dhTestMessages)
+			}
+		}
+	}
+}
+
+func TestSRPLogin(t *testing.T) {
+	// given
+	email, password := "alice@example.com", "correct horse battery staple"
+	server := NewSRPServer()
+	if err := server.Register(email, password); err != nil {
+		t.Fatalf("Error registering user: %s", err.Error())
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %s", err.Error())
+	}
+	defer listener.Close()
+	go server.Serve(listener)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Error connecting to server: %s", err.Error())
+	}
+	defer conn.Close()
+
+	// when
+	welcome, err := SRPLogin(conn, email, password)
+	if err != nil {
+		t.Fatalf("Error logging in: %s", err.Error())
+	}
+
+	// then
+	if !bytes.Equal(welcome, srpWelcomeMessage(email)) {
+		t.Errorf("SRPLogin(...) = %s, expected %s", welcome, srpWelcomeMessage(email))
+	}
+}
+
+func TestSRPLoginWrongPassword(t *testing.T) {
+	// given
+	email := "alice@example.com"
+	server := NewSRPServer()
+	if err := server.Register(email, "correct horse battery staple"); err != nil {
+		t.Fatalf("Error registering user: %s", err.Error())
+	}
+
+	clientConn, serverConn := net.Pipe()
+	go server.ServeConn(serverConn)
+
+	// when
+	_, err := SRPLogin(clientConn, email, "wrong password")
+
+	// then
+	if err == nil {
+		t.Errorf("SRPLogin(...) with a wrong password succeeded")
+	}
+}
+
+func TestSRPLoginRejectsZeroServerKey(t *testing.T) {
+	// given
+	// a malicious server answering with B = N, which is 0 mod N
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+	go func() {
+		decoder, encoder := json.NewDecoder(serverConn), json.NewEncoder(serverConn)
+		var hello srpMessage
+		if decoder.Decode(&hello) == nil {
+			encoder.Encode(srpMessage{Salt: []byte("salt"), PublicKey: dh.P})
+		}
+	}()
+
+	// when
+	_, err := SRPLogin(clientConn, "alice@example.com", "correct horse battery staple")
+
+	// then
+	if err == nil {
+		t.Errorf("SRPLogin(...) accepted a server public key equal to 0 mod N")
+	}
+}
+
+func TestSRPZeroKeyLogin(t *testing.T) {
+	// given
+	email := "alice@example.com"
+	server := NewSRPServer()
+	if err := server.Register(email, "correct horse battery staple"); err != nil {
+		t.Fatalf("Error registering user: %s", err.Error())
+	}
+
+	publicKeys := []*big.Int{
+		big.NewInt(0),
+		dh.P,
+		new(big.Int).Mul(dh.P, big.NewInt(2)),
+	}
+
+	for _, publicKey := range publicKeys {
+		clientConn, serverConn := net.Pipe()
+		go server.ServeConn(serverConn)
+
+		// when
+		welcome, err := SRPZeroKeyLogin(clientConn, email, publicKey)
+		if err != nil {
+			t.Fatalf("Error logging in with A=%x: %s", publicKey, err.Error())
+		}
+
+		// then
+		if !bytes.Equal(welcome, srpWelcomeMessage(email)) {
+			t.Errorf("SRPZeroKeyLogin(..., %x) = %s, expected %s", publicKey, welcome, srpWelcomeMessage(email))
+		}
+	}
+}
+
+func TestSimpleSRPLogin(t *testing.T) {
+	// given
+	email, password := "alice@example.com", "monkey"
+	server := NewSimpleSRPServer()
+	if err := server.Register(email, password); err != nil {
+		t.Fatalf("Error registering user: %s", err.Error())
+	}
+
+	clientConn, serverConn := net.Pipe()
+	go server.ServeConn(serverConn)
+
+	// when
+	welcome, err := SimpleSRPLogin(clientConn, email, password)
+	if err != nil {
+		t.Fatalf("Error logging in: %s", err.Error())
+	}
+
+	// then
+	if !bytes.Equal(welcome, srpWelcomeMessage(email)) {
+		t.Errorf("SimpleSRPLogin(...) = %s, expected %s", welcome, srpWelcomeMessage(email))
+	}
+}
+
+func TestSimpleSRPDictionaryAttack(t *testing.T) {
+	// given
+	password := "sunshine"
+	dictionary := []string{"123456", "password", "qwerty", "monkey", "dragon", "sunshine", "letmein"}
+
+	clientConn, attackerConn := net.Pipe()
+	go SimpleSRPLogin(clientConn, "alice@example.com", password)
+
+	// when
+	crackedPassword, err := SimpleSRPDictionaryAttack(attackerConn, dictionary)
+	if err != nil {
+		t.Fatalf("Error running dictionary attack: %s", err.Error())
+	}
+
+	// then
+	if crackedPassword != password {
+		t.Errorf("SimpleSRPDictionaryAttack(...) = %s, expected %s", crackedPassword, password)
+	}
+}
+
+func TestRSABroadcastAttack(t *testing.T) {
+	// given
+	// about 750 bits, m^3 is larger than every 1024 bits modulus so the
+	// ciphertexts are really reduced and the CRT is needed
+	message := []byte("attack at dawn, all three of them, and bring enough coffee for the whole night shift please!!")
+	m := new(big.Int).SetBytes(message)
+
+	var ciphertexts []*big.Int
+	var publicKeys []*rsa.PublicKey
+	for i := 0; i < 3; i++ {
+		privateKey, err := rsa.GenerateKey(1024, 3)
+		if err != nil {
+			t.Fatalf("Error generating key: %s", err.Error())
+		}
+
+		publicKeys = append(publicKeys, &privateKey.PublicKey)
+		ciphertexts = append(ciphertexts, rsa.Encrypt(&privateKey.PublicKey, m))
+	}
+
+	if rsa.CubeRoot(ciphertexts[0]).Cmp(m) == 0 {
+		t.Fatalf("Cube root of a single ciphertext recovers the message, the CRT is not exercised")
+	}
+
+	// when
+	recovered, err := RSABroadcastAttack(ciphertexts, publicKeys)
+	if err != nil {
+		t.Fatalf("Error running broadcast attack: %s", err.Error())
+	}
+
+	// then
+	if !bytes.Equal(recovered.Bytes(), message) {
+		t.Errorf("RSABroadcastAttack(...) = %s, expected %s", recovered.Bytes(), message)
+	}
+}
@@ -0,0 +1,606 @@
+package cryptochallenges
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ka3de/go-cryptochallenges/dsa"
+	"github.com/ka3de/go-cryptochallenges/rsa"
+)
+
+// RSAParityOracle - decrypts an RSA ciphertext and only reveals whether
+// its plaintext is even
+type RSAParityOracle func(ciphertext *big.Int) bool
+
+// NewRSAParityOracle - returns a parity oracle for the given private key
+func NewRSAParityOracle(privateKey *rsa.PrivateKey) RSAParityOracle {
+	return func(ciphertext *big.Int) bool {
+		return rsa.Decrypt(privateKey, ciphertext).Bit(0) == 0
+	}
+}
+
+// RSAParityAttack - recovers the plaintext of ciphertext by repeatedly
+// doubling it and asking the oracle for the parity of the result,
+// every answer halves the [lower, upper) interval the plaintext lies in.
+// progress (if not nil) is called with the current upper bound after each query
+func RSAParityAttack(ciphertext *big.Int, publicKey *rsa.PublicKey,
+	oracle RSAParityOracle, progress func(upperBound *big.Int)) *big.Int {
+
+	// multiplying the ciphertext by 2^e doubles the plaintext
+	doubler := rsa.Encrypt(publicKey, big.NewInt(2))
+	c := new(big.Int).Set(ciphertext)
+
+	lower := new(big.Rat)
+	upper := new(big.Rat).SetInt(publicKey.N)
+	two := big.NewRat(2, 1)
+
+	for i := 0; i < publicKey.N.BitLen(); i++ {
+		c.Mul(c, doubler)
+		c.Mod(c, publicKey.N)
+
+		middle := new(big.Rat).Add(lower, upper)
+		middle.Quo(middle, two)
+
+		// an even plaintext means doubling did not wrap the modulus
+		if oracle(c) {
+			upper = middle
+		} else {
+			lower = middle
+		}
+
+		if progress != nil {
+			progress(new(big.Int).Quo(upper.Num(), upper.Denom()))
+		}
+	}
+
+	// the interval is narrower than 1, the plaintext is the only integer in it
+	m, remainder := new(big.Int).QuoRem(lower.Num(), lower.Denom(), new(big.Int))
+	if remainder.Sign() != 0 {
+		m.Add(m, big.NewInt(1))
+	}
+
+	return m
+}
+
+// RSAPaddingOracle - decrypts an RSA ciphertext and only reveals whether
+// its plaintext is PKCS#1 v1.5 conforming (starts with 00 02)
+type RSAPaddingOracle func(ciphertext *big.Int) bool
+
+// NewRSAPaddingOracle - returns a PKCS#1 v1.5 padding oracle for the given private key
+func NewRSAPaddingOracle(privateKey *rsa.PrivateKey) RSAPaddingOracle {
+	k := privateKey.Size()
+	return func(ciphertext *big.Int) bool {
+		plaintext := rsa.IntToBytes(rsa.Decrypt(privateKey, ciphertext), k)
+		return len(plaintext) == k && plaintext[0] == 0 && plaintext[1] == 2
+	}
+}
+
+// bleichenbacherInterval - closed interval [a, b] the plaintext may lie in
+type bleichenbacherInterval struct {
+	a, b *big.Int
+}
+
+// ceilDiv - returns ceil(x / y) for positive y
+func ceilDiv(x, y *big.Int) *big.Int {
+	q, m := new(big.Int).DivMod(x, y, new(big.Int))
+	if m.Sign() != 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+// mergeIntervals - returns the union of the given intervals as a sorted
+// list of disjoint intervals
+func mergeIntervals(intervals []bleichenbacherInterval) []bleichenbacherInterval {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].a.Cmp(intervals[j].a) < 0
+	})
+
+	var merged []bleichenbacherInterval
+	for _, interval := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && interval.a.Cmp(merged[last].b) <= 0 {
+			if interval.b.Cmp(merged[last].b) > 0 {
+				merged[last].b = interval.b
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+
+	return merged
+}
+
+// BleichenbacherAttack - recovers the (padded) plaintext of ciphertext using
+// a PKCS#1 v1.5 padding oracle, following Bleichenbacher's 1998 paper.
+// Returns the plaintext and the number of oracle queries it took
+func BleichenbacherAttack(ciphertext *big.Int, publicKey *rsa.PublicKey,
+	oracle RSAPaddingOracle) (*big.Int, int, error) {
+
+	n := publicKey.N
+	k := publicKey.Size()
+	if k < 11 {
+		return nil, 0, errors.New("modulus too small for PKCS#1 v1.5")
+	}
+
+	queries := 0
+	// conforming - checks whether c * s^e is PKCS#1 conforming
+	conforming := func(c, s *big.Int) bool {
+		queries++
+		blinded := rsa.Encrypt(publicKey, s)
+		blinded.Mul(blinded, c)
+		blinded.Mod(blinded, n)
+		return oracle(blinded)
+	}
+
+	one := big.NewInt(1)
+	b := new(big.Int).Lsh(one, uint(8*(k-2)))
+	twoB := new(big.Int).Lsh(b, 1)
+	threeB := new(big.Int).Add(twoB, b)
+	threeBMinusOne := new(big.Int).Sub(threeB, one)
+
+	// step 1: blinding, only needed if ciphertext is not conforming already
+	s0 := big.NewInt(1)
+	for !conforming(ciphertext, s0) {
+		var err error
+		if s0, err = rand.Int(rand.Reader, n); err != nil {
+			return nil, queries, err
+		}
+	}
+	c0 := rsa.Encrypt(publicKey, s0)
+	c0.Mul(c0, ciphertext)
+	c0.Mod(c0, n)
+
+	intervals := []bleichenbacherInterval{{a: twoB, b: threeBMinusOne}}
+	var s *big.Int
+
+	for i := 1; ; i++ {
+		switch {
+		case i == 1:
+			// step 2a: smallest s >= n/3B that gives a conforming plaintext
+			s = ceilDiv(n, threeB)
+			for !conforming(c0, s) {
+				s.Add(s, one)
+			}
+
+		case len(intervals) > 1:
+			// step 2b: keep searching upwards with more than one interval left
+			s = new(big.Int).Add(s, one)
+			for !conforming(c0, s) {
+				s.Add(s, one)
+			}
+
+		default:
+			// step 2c: one interval left, search small r and s values
+			a, bound := intervals[0].a, intervals[0].b
+			r := new(big.Int).Mul(bound, s)
+			r.Sub(r, twoB)
+			r.Lsh(r, 1)
+			r = ceilDiv(r, n)
+
+			found := false
+			for !found {
+				rn := new(big.Int).Mul(r, n)
+				sLow := ceilDiv(new(big.Int).Add(twoB, rn), bound)
+				sHigh := ceilDiv(new(big.Int).Add(threeB, rn), a)
+
+				for candidate := sLow; candidate.Cmp(sHigh) < 0; candidate.Add(candidate, one) {
+					if conforming(c0, candidate) {
+						s = new(big.Int).Set(candidate)
+						found = true
+						break
+					}
+				}
+				r.Add(r, one)
+			}
+		}
+
+		// step 3: narrow the set of solutions
+		var narrowed []bleichenbacherInterval
+		for _, interval := range intervals {
+			rLow := new(big.Int).Mul(interval.a, s)
+			rLow.Sub(rLow, threeBMinusOne)
+			rLow = ceilDiv(rLow, n)
+
+			rHigh := new(big.Int).Mul(interval.b, s)
+			rHigh.Sub(rHigh, twoB)
+			rHigh.Div(rHigh, n)
+
+			for r := rLow; r.Cmp(rHigh) <= 0; r = new(big.Int).Add(r, one) {
+				rn := new(big.Int).Mul(r, n)
+
+				a := ceilDiv(new(big.Int).Add(twoB, rn), s)
+				if a.Cmp(interval.a) < 0 {
+					a = interval.a
+				}
+
+				bound := new(big.Int).Add(threeBMinusOne, rn)
+				bound.Div(bound, s)
+				if bound.Cmp(interval.b) > 0 {
+					bound = interval.b
+				}
+
+				if a.Cmp(bound) <= 0 {
+					narrowed = append(narrowed, bleichenbacherInterval{a: a, b: bound})
+				}
+			}
+		}
+		if len(narrowed) == 0 {
+			return nil, queries, errors.New("no interval left, oracle is not consistent")
+		}
+		intervals = mergeIntervals(narrowed)
+
+		// step 4: done once the interval has a single value
+		if len(intervals) == 1 && intervals[0].a.Cmp(intervals[0].b) == 0 {
+			s0Inverse, err := rsa.ModInverse(s0, n)
+			if err != nil {
+				return nil, queries, err
+			}
+
+			m := new(big.Int).Mul(intervals[0].a, s0Inverse)
+			return m.Mod(m, n), queries, nil
+		}
+	}
+}
+
+// DSASignedMessage - message signed with DSA along with its SHA-1 hash and signature
+type DSASignedMessage struct {
+	Message []byte
+	Hash    []byte
+	R       *big.Int
+	S       *big.Int
+}
+
+// DSAPrivateKeyFromNonce - returns the private key x = (s*k - H(m)) / r mod q
+// of a signature (r, s) whose nonce k is known
+func DSAPrivateKeyFromNonce(publicKey *dsa.PublicKey, hash []byte, r, s, k *big.Int) (*dsa.PrivateKey, error) {
+	q := publicKey.Q
+
+	rInverse := new(big.Int).ModInverse(r, q)
+	if rInverse == nil {
+		return nil, errors.New("r is not invertible")
+	}
+
+	x := new(big.Int).Mul(s, k)
+	x.Sub(x, new(big.Int).SetBytes(hash))
+	x.Mul(x, rInverse)
+	x.Mod(x, q)
+
+	return &dsa.PrivateKey{PublicKey: *publicKey, X: x}, nil
+}
+
+// isDSAPrivateKey - checks whether x is the private key matching y
+func isDSAPrivateKey(privateKey *dsa.PrivateKey) bool {
+	y := new(big.Int).Exp(privateKey.G, privateKey.X, privateKey.P)
+	return y.Cmp(privateKey.Y) == 0
+}
+
+// DSABruteForceNonce - recovers the private key from a signature whose nonce
+// is known to be in [0, maxNonce], walking g^k mod p for every nonce until
+// it matches r and checking the derived private key against the public key
+func DSABruteForceNonce(publicKey *dsa.PublicKey, hash []byte, r, s *big.Int,
+	maxNonce int64) (*dsa.PrivateKey, error) {
+
+	gk := big.NewInt(1)
+	rCandidate := new(big.Int)
+
+	for k := int64(0); k <= maxNonce; k++ {
+		if rCandidate.Mod(gk, publicKey.Q).Cmp(r) == 0 {
+			privateKey, err := DSAPrivateKeyFromNonce(publicKey, hash, r, s, big.NewInt(k))
+			if err != nil {
+				return nil, err
+			}
+
+			if isDSAPrivateKey(privateKey) {
+				return privateKey, nil
+			}
+		}
+
+		gk.Mul(gk, publicKey.G)
+		gk.Mod(gk, publicKey.P)
+	}
+
+	return nil, errors.New("nonce not found")
+}
+
+// DSANonceReuseAttack - looks for two signatures sharing the same nonce
+// (same r) in messages and recovers the private key from them,
+// k = (m1 - m2) / (s1 - s2) mod q
+func DSANonceReuseAttack(publicKey *dsa.PublicKey, messages []DSASignedMessage) (*dsa.PrivateKey, error) {
+	q := publicKey.Q
+
+	for i := 0; i < len(messages); i++ {
+		for j := i + 1; j < len(messages); j++ {
+			first, second := messages[i], messages[j]
+			if first.R.Cmp(second.R) != 0 {
+				continue
+			}
+
+			sDiff := new(big.Int).Sub(first.S, second.S)
+			sDiffInverse := new(big.Int).ModInverse(sDiff.Mod(sDiff, q), q)
+			if sDiffInverse == nil {
+				continue // same message signed twice
+			}
+
+			k := new(big.Int).Sub(new(big.Int).SetBytes(first.Hash), new(big.Int).SetBytes(second.Hash))
+			k.Mul(k, sDiffInverse)
+			k.Mod(k, q)
+
+			privateKey, err := DSAPrivateKeyFromNonce(publicKey, first.Hash, first.R, first.S, k)
+			if err != nil {
+				return nil, err
+			}
+			if isDSAPrivateKey(privateKey) {
+				return privateKey, nil
+			}
+		}
+	}
+
+	return nil, errors.New("no reused nonce found")
+}
+
+// DSAZeroGeneratorSignature - returns a signature that validates against any
+// message for a public key whose parameters have been tampered with g = 0,
+// by a verifier that does not check that r is not zero
+func DSAZeroGeneratorSignature(publicKey *dsa.PublicKey) (*big.Int, *big.Int, error) {
+	// g^u1 = 0 makes v = 0 whatever the message and s are
+	s, err := rand.Int(rand.Reader, new(big.Int).Sub(publicKey.Q, big.NewInt(1)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return big.NewInt(0), s.Add(s, big.NewInt(1)), nil
+}
+
+// DSAMagicSignature - returns a signature that validates against any message
+// for a public key whose parameters have been tampered with g = p+1,
+// r = (y^z mod p) mod q and s = r / z mod q for an arbitrary z
+func DSAMagicSignature(publicKey *dsa.PublicKey) (*big.Int, *big.Int, error) {
+	q := publicKey.Q
+
+	for {
+		z, err := rand.Int(rand.Reader, q)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		zInverse := new(big.Int).ModInverse(z, q)
+		if zInverse == nil {
+			continue
+		}
+
+		// g^u1 = 1 so v = y^u2 mod p mod q with u2 = r/s = z
+		r := new(big.Int).Exp(publicKey.Y, z, publicKey.P)
+		r.Mod(r, q)
+
+		s := new(big.Int).Mul(r, zInverse)
+		s.Mod(s, q)
+
+		if r.Sign() != 0 && s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+}
+
+// SloppyVerifyPKCS1v15 - verifies a PKCS#1 v1.5 signature the wrong way: it
+// parses the block from the left and stops right after the hash, without
+// checking that the hash is right-justified in the block
+func SloppyVerifyPKCS1v15(publicKey *rsa.PublicKey, hash crypto.Hash, hashed []byte, signature *big.Int) bool {
+	encoded := rsa.IntToBytes(rsa.Encrypt(publicKey, signature), publicKey.Size())
+	if len(encoded) < 3 || encoded[0] != 0 || encoded[1] != 1 || encoded[2] != 0xff {
+		return false
+	}
+
+	// skip the FF padding bytes up to the 00 separator
+	i := 2
+	for i < len(encoded) && encoded[i] == 0xff {
+		i++
+	}
+	if i == len(encoded) || encoded[i] != 0 {
+		return false
+	}
+
+	digestInfo, err := rsa.DigestInfo(hash, hashed)
+	if err != nil {
+		return false
+	}
+
+	// whatever comes after the DigestInfo is ignored
+	return bytes.HasPrefix(encoded[i+1:], digestInfo)
+}
+
+// ForgePKCS1v15Signature - forges a signature for the hashed message that is
+// accepted by SloppyVerifyPKCS1v15 for any e=3 public key. The block
+// 00 01 FF 00 DigestInfo is followed by as much garbage as needed for its
+// cube root to be computed without knowing the private key
+func ForgePKCS1v15Signature(publicKey *rsa.PublicKey, hash crypto.Hash, hashed []byte) (*big.Int, error) {
+	if publicKey.E.Cmp(big.NewInt(3)) != 0 {
+		return nil, errors.New("public exponent must be 3")
+	}
+
+	digestInfo, err := rsa.DigestInfo(hash, hashed)
+	if err != nil {
+		return nil, err
+	}
+
+	k := publicKey.Size()
+	prefix := append([]byte{0x00, 0x01, 0xff, 0x00}, digestInfo...)
+	if len(prefix) >= k {
+		return nil, errors.New("modulus too small for the hash function")
+	}
+
+	// any cube between the prefix followed by 00s and
+	// the prefix followed by FFs is a valid forgery
+	low := make([]byte, k)
+	high := bytes.Repeat([]byte{0xff}, k)
+	copy(low, prefix)
+	copy(high, prefix)
+
+	signature := rsa.CubeRoot(new(big.Int).SetBytes(high))
+	cube := new(big.Int).Exp(signature, big.NewInt(3), nil)
+	if cube.Cmp(new(big.Int).SetBytes(low)) < 0 {
+		return nil, errors.New("not enough garbage room to forge the signature")
+	}
+
+	return signature, nil
+}
+
+// RSADecryptionOracle - decrypts RSA ciphertexts on behalf of the caller
+type RSADecryptionOracle func(ciphertext *big.Int) (*big.Int, error)
+
+// RSADecryptionService - decrypts RSA ciphertexts, but refuses to decrypt
+// the same ciphertext twice within a time window
+type RSADecryptionService struct {
+	privateKey *rsa.PrivateKey
+	window     time.Duration
+	now        func() time.Time
+
+	mu   sync.Mutex
+	seen map[[sha256.Size]byte]time.Time
+}
+
+// rsaDecryptionRequest - body of the HTTP decryption requests
+type rsaDecryptionRequest struct {
+	Ciphertext *big.Int `json:"ciphertext"`
+}
+
+// rsaDecryptionResponse - body of the HTTP decryption responses
+type rsaDecryptionResponse struct {
+	Plaintext *big.Int `json:"plaintext"`
+}
+
+// NewRSADecryptionService - returns a new decryption service for privateKey
+// that remembers decrypted ciphertexts for window
+func NewRSADecryptionService(privateKey *rsa.PrivateKey, window time.Duration) *RSADecryptionService {
+	return &RSADecryptionService{
+		privateKey: privateKey,
+		window:     window,
+		now:        time.Now,
+		seen:       make(map[[sha256.Size]byte]time.Time),
+	}
+}
+
+// PublicKey - returns the public key matching the service private key
+func (s *RSADecryptionService) PublicKey() *rsa.PublicKey {
+	return &s.privateKey.PublicKey
+}
+
+// Decrypt - decrypts ciphertext unless it has already been decrypted within the window
+func (s *RSADecryptionService) Decrypt(ciphertext *big.Int) (*big.Int, error) {
+	// c + n decrypts like c, only the reduced representative is accepted
+	if ciphertext.Sign() < 0 || ciphertext.Cmp(s.privateKey.N) >= 0 {
+		return nil, errors.New("ciphertext out of range")
+	}
+
+	digest := sha256.Sum256(ciphertext.Bytes())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for seenDigest, seenAt := range s.seen {
+		if now.Sub(seenAt) >= s.window {
+			delete(s.seen, seenDigest)
+		}
+	}
+
+	if _, ok := s.seen[digest]; ok {
+		return nil, errors.New("ciphertext already decrypted")
+	}
+	s.seen[digest] = now
+
+	return rsa.Decrypt(s.privateKey, ciphertext), nil
+}
+
+// ServeHTTP - decrypts the JSON encoded ciphertext posted in the request body
+func (s *RSADecryptionService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request rsaDecryptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Ciphertext == nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	plaintext, err := s.Decrypt(request.Ciphertext)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rsaDecryptionResponse{Plaintext: plaintext})
+}
+
+// NewRSADecryptionHTTPOracle - returns a decryption oracle backed by the
+// RSADecryptionService HTTP endpoint at url
+func NewRSADecryptionHTTPOracle(client *http.Client, url string) RSADecryptionOracle {
+	return func(ciphertext *big.Int) (*big.Int, error) {
+		body, err := json.Marshal(rsaDecryptionRequest{Ciphertext: ciphertext})
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("decryption service returned %s", resp.Status)
+		}
+
+		var response rsaDecryptionResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return nil, err
+		}
+		return response.Plaintext, nil
+	}
+}
+
+// RSAUnpaddedMessageRecovery - recovers the plaintext of a ciphertext the
+// oracle refuses to decrypt again, by blinding it as c' = s^e * c mod n,
+// getting c' decrypted and unblinding the result p' as p = p' / s mod n
+func RSAUnpaddedMessageRecovery(ciphertext *big.Int, publicKey *rsa.PublicKey,
+	oracle RSADecryptionOracle) (*big.Int, error) {
+
+	n := publicKey.N
+
+	var s, sInverse *big.Int
+	for sInverse == nil {
+		var err error
+		if s, err = rand.Int(rand.Reader, n); err != nil {
+			return nil, err
+		}
+		if s.Cmp(big.NewInt(1)) <= 0 {
+			continue
+		}
+		sInverse, _ = rsa.ModInverse(s, n)
+	}
+
+	blinded := rsa.Encrypt(publicKey, s)
+	blinded.Mul(blinded, ciphertext)
+	blinded.Mod(blinded, n)
+
+	blindedPlaintext, err := oracle(blinded)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := new(big.Int).Mul(blindedPlaintext, sInverse)
+	return plaintext.Mod(plaintext, n), nil
+}
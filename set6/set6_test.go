@@ -0,0 +1,335 @@
+package cryptochallenges
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ka3de/go-cryptochallenges/dsa"
+	"github.com/ka3de/go-cryptochallenges/rsa"
+)
+
+func TestRSAParityAttack(t *testing.T) {
+	// given
+	message, _ := base64.StdEncoding.DecodeString("VGhhdCdzIHdoeSBJIGZvdW5kIHlvdSBkb24ndCBwbGF5IGF" +
+		"yb3VuZCB3aXRoIHRoZSBGdW5reSBDb2xkIE1lZGluYQ==")
+
+	privateKey, err := rsa.GenerateKey(1024, 65537)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	ciphertext := rsa.Encrypt(&privateKey.PublicKey, new(big.Int).SetBytes(message))
+	oracle := NewRSAParityOracle(privateKey)
+
+	queries := 0
+	progress := func(upperBound *big.Int) {
+		queries++
+	}
+
+	// when
+	recovered := RSAParityAttack(ciphertext, &privateKey.PublicKey, oracle, progress)
+
+	// then
+	if !bytes.Equal(recovered.Bytes(), message) {
+		t.Errorf("RSAParityAttack(...) = %q, expected %q", recovered.Bytes(), message)
+	}
+	if queries != privateKey.N.BitLen() {
+		t.Errorf("RSAParityAttack(...) made %d queries, expected %d", queries, privateKey.N.BitLen())
+	}
+}
+
+func TestBleichenbacherAttack(t *testing.T) {
+	// given
+	message := []byte("kick it, CC")
+
+	privateKey, err := rsa.GenerateKey(256, 3)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+
+	padded, err := rsa.PadPKCS1v15Encryption(message, privateKey.Size())
+	if err != nil {
+		t.Fatalf("Error padding message: %s", err.Error())
+	}
+	ciphertext := rsa.Encrypt(&privateKey.PublicKey, new(big.Int).SetBytes(padded))
+	oracle := NewRSAPaddingOracle(privateKey)
+
+	// when
+	recovered, _, err := BleichenbacherAttack(ciphertext, &privateKey.PublicKey, oracle)
+	if err != nil {
+		t.Fatalf("Error running Bleichenbacher attack: %s", err.Error())
+	}
+	plaintext, err := rsa.UnpadPKCS1v15Encryption(rsa.IntToBytes(recovered, privateKey.Size()))
+	if err != nil {
+		t.Fatalf("Error unpadding recovered plaintext: %s", err.Error())
+	}
+
+	// then
+	if !bytes.Equal(plaintext, message) {
+		t.Errorf("BleichenbacherAttack(...) = %q, expected %q", plaintext, message)
+	}
+}
+
+func BenchmarkBleichenbacherAttack(b *testing.B) {
+	for _, bits := range []int{256, 512, 768} {
+		b.Run(fmt.Sprintf("%d-bits", bits), func(b *testing.B) {
+			privateKey, err := rsa.GenerateKey(bits, 3)
+			if err != nil {
+				b.Fatalf("Error generating key: %s", err.Error())
+			}
+			oracle := NewRSAPaddingOracle(privateKey)
+
+			totalQueries := 0
+			for i := 0; i < b.N; i++ {
+				padded, err := rsa.PadPKCS1v15Encryption([]byte("kick it, CC"), privateKey.Size())
+				if err != nil {
+					b.Fatalf("Error padding message: %s", err.Error())
+				}
+				m := new(big.Int).SetBytes(padded)
+
+				recovered, queries, err := BleichenbacherAttack(rsa.Encrypt(&privateKey.PublicKey, m),
+					&privateKey.PublicKey, oracle)
+				if err != nil {
+					b.Fatalf("Error running Bleichenbacher attack: %s", err.Error())
+				}
+				if recovered.Cmp(m) != 0 {
+					b.Fatalf("BleichenbacherAttack(...) = %x, expected %x", recovered, m)
+				}
+				totalQueries += queries
+			}
+
+			b.ReportMetric(float64(totalQueries)/float64(b.N), "queries/op")
+		})
+	}
+}
+
+func TestDSABruteForceNonce(t *testing.T) {
+	// given
+	y, _ := new(big.Int).SetString("84ad4719d044495496a3201c8ff484feb45b962e7302e56a392aee4abab3e4bdebf2955b473"+
+		"6012f21a08084056b19bcd7fee56048e004e44984e2f411788efdc837a0d2e5abb7b555039fd243ac01f0fb2ed1dec5682"+
+		"80ce678e931868d23eb095fde9d3779191b8c0299d6e07bbb283e6633451e535c45513b2d33c99ea17", 16)
+	publicKey := &dsa.PublicKey{Parameters: dsa.DefaultParameters, Y: y}
+
+	hash := sha1.Sum([]byte("For those that envy a MC it can be hazardous to your health\n" +
+		"So be friendly, a matter of life and death, just like a etch-a-sketch\n"))
+	r, _ := new(big.Int).SetString("548099063082341131477253921760299949438196259240", 10)
+	s, _ := new(big.Int).SetString("857042759984254168557880549501802188789837994940", 10)
+
+	expectedFingerprint := "0954edd5e0afe5542a4adf012611a91912a3ec16"
+
+	// when
+	privateKey, err := DSABruteForceNonce(publicKey, hash[:], r, s, 1<<16)
+	if err != nil {
+		t.Fatalf("Error brute forcing DSA nonce: %s", err.Error())
+	}
+
+	// then
+	fingerprint := sha1.Sum([]byte(privateKey.X.Text(16)))
+	if hex.EncodeToString(fingerprint[:]) != expectedFingerprint {
+		t.Errorf("DSABruteForceNonce(...) fingerprint = %x, expected %s", fingerprint, expectedFingerprint)
+	}
+}
+
+func TestDSANonceReuseAttack(t *testing.T) {
+	// given
+	privateKey, err := dsa.GenerateKey(dsa.DefaultParameters)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+
+	texts := []string{
+		"Listen for me, you better listen for me now. ",
+		"Yes, I'm a man of a lot of words. ",
+		"My ears ring from the night before. ",
+		"Pure black hood, I'm a bandit. ",
+		"Then the people cry, pure and the youth. ",
+	}
+	reusedNonce := big.NewInt(1234567)
+
+	var messages []DSASignedMessage
+	for i, text := range texts {
+		hash := sha1.Sum([]byte(text))
+
+		var r, s *big.Int
+		if i == 1 || i == 3 {
+			r, s, err = dsa.SignWithNonce(privateKey, hash[:], reusedNonce)
+		} else {
+			r, s, err = dsa.Sign(privateKey, hash[:])
+		}
+		if err != nil {
+			t.Fatalf("Error signing message: %s", err.Error())
+		}
+
+		messages = append(messages, DSASignedMessage{Message: []byte(text), Hash: hash[:], R: r, S: s})
+	}
+
+	// when
+	recoveredKey, err := DSANonceReuseAttack(&privateKey.PublicKey, messages)
+	if err != nil {
+		t.Fatalf("Error running nonce reuse attack: %s", err.Error())
+	}
+
+	// then
+	if recoveredKey.X.Cmp(privateKey.X) != 0 {
+		t.Errorf("DSANonceReuseAttack(...) = %x, expected %x", recoveredKey.X, privateKey.X)
+	}
+}
+
+func TestDSAMagicSignatures(t *testing.T) {
+	// given
+	privateKey, err := dsa.GenerateKey(dsa.DefaultParameters)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+
+	zeroGeneratorKey := privateKey.PublicKey
+	zeroGeneratorKey.G = big.NewInt(0)
+
+	plusOneGeneratorKey := privateKey.PublicKey
+	plusOneGeneratorKey.G = new(big.Int).Add(privateKey.P, big.NewInt(1))
+
+	// when
+	zeroR, zeroS, err := DSAZeroGeneratorSignature(&zeroGeneratorKey)
+	if err != nil {
+		t.Fatalf("Error generating g=0 magic signature: %s", err.Error())
+	}
+	magicR, magicS, err := DSAMagicSignature(&plusOneGeneratorKey)
+	if err != nil {
+		t.Fatalf("Error generating g=p+1 magic signature: %s", err.Error())
+	}
+
+	// then
+	for _, message := range []string{"Hello, world", "Goodbye, world"} {
+		hash := sha1.Sum([]byte(message))
+
+		if !dsa.VerifyUnchecked(&zeroGeneratorKey, hash[:], zeroR, zeroS) {
+			t.Errorf("g=0 magic signature rejected for %q", message)
+		}
+		if !dsa.Verify(&plusOneGeneratorKey, hash[:], magicR, magicS) {
+			t.Errorf("g=p+1 magic signature rejected for %q", message)
+		}
+	}
+}
+
+func TestForgePKCS1v15Signature(t *testing.T) {
+	tests := []struct {
+		bits   int
+		hash   crypto.Hash
+		hashed []byte
+	}{
+		{1024, crypto.SHA1, func() []byte { h := sha1.Sum([]byte("hi mom")); return h[:] }()},
+		{2048, crypto.SHA256, func() []byte { h := sha256.Sum256([]byte("hi mom")); return h[:] }()},
+	}
+
+	for _, test := range tests {
+		// given
+		privateKey, err := rsa.GenerateKey(test.bits, 3)
+		if err != nil {
+			t.Fatalf("Error generating key: %s", err.Error())
+		}
+		publicKey := &privateKey.PublicKey
+
+		// when
+		signature, err := ForgePKCS1v15Signature(publicKey, test.hash, test.hashed)
+		if err != nil {
+			t.Fatalf("Error forging signature: %s", err.Error())
+		}
+		genuineSignature, err := rsa.SignPKCS1v15(privateKey, test.hash, test.hashed)
+		if err != nil {
+			t.Fatalf("Error signing: %s", err.Error())
+		}
+
+		// then
+		if !SloppyVerifyPKCS1v15(publicKey, test.hash, test.hashed, genuineSignature) {
+			t.Errorf("SloppyVerifyPKCS1v15(...) rejected genuine signature for %d bits", test.bits)
+		}
+		if !SloppyVerifyPKCS1v15(publicKey, test.hash, test.hashed, signature) {
+			t.Errorf("SloppyVerifyPKCS1v15(...) rejected forged signature for %d bits", test.bits)
+		}
+		if rsa.VerifyPKCS1v15(publicKey, test.hash, test.hashed, signature) {
+			t.Errorf("VerifyPKCS1v15(...) accepted forged signature for %d bits", test.bits)
+		}
+	}
+}
+
+func TestRSADecryptionServiceRefusesReplays(t *testing.T) {
+	// given
+	privateKey, err := rsa.GenerateKey(512, 65537)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	service := NewRSADecryptionService(privateKey, time.Minute)
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	ciphertext := rsa.Encrypt(service.PublicKey(), big.NewInt(42))
+
+	// when
+	_, firstErr := service.Decrypt(ciphertext)
+	_, replayErr := service.Decrypt(ciphertext)
+	_, unreducedErr := service.Decrypt(new(big.Int).Add(ciphertext, service.PublicKey().N))
+	now = now.Add(time.Minute)
+	_, expiredErr := service.Decrypt(ciphertext)
+
+	// then
+	if firstErr != nil {
+		t.Errorf("Error decrypting ciphertext: %s", firstErr.Error())
+	}
+	if replayErr == nil {
+		t.Errorf("Decrypt(...) accepted a replayed ciphertext")
+	}
+	if unreducedErr == nil {
+		t.Errorf("Decrypt(...) accepted a replayed ciphertext plus the modulus")
+	}
+	if expiredErr != nil {
+		t.Errorf("Error decrypting ciphertext after the window: %s", expiredErr.Error())
+	}
+}
+
+func TestRSAUnpaddedMessageRecovery(t *testing.T) {
+	// given
+	message := []byte(`{time: 1356304276, social: '555-55-5555'}`)
+
+	privateKey, err := rsa.GenerateKey(1024, 65537)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	service := NewRSADecryptionService(privateKey, time.Minute)
+	httpService := NewRSADecryptionService(privateKey, time.Minute)
+	server := httptest.NewServer(httpService)
+	defer server.Close()
+
+	oracles := map[string]RSADecryptionOracle{
+		"in-process": service.Decrypt,
+		"http":       NewRSADecryptionHTTPOracle(server.Client(), server.URL),
+	}
+
+	for name, oracle := range oracles {
+		ciphertext := rsa.Encrypt(service.PublicKey(), new(big.Int).SetBytes(message))
+		if _, err := oracle(ciphertext); err != nil {
+			t.Fatalf("Error decrypting captured ciphertext (%s): %s", name, err.Error())
+		}
+		if _, err := oracle(ciphertext); err == nil {
+			t.Fatalf("Oracle (%s) decrypted the same ciphertext twice", name)
+		}
+
+		// when
+		recovered, err := RSAUnpaddedMessageRecovery(ciphertext, service.PublicKey(), oracle)
+		if err != nil {
+			t.Fatalf("Error recovering message (%s): %s", name, err.Error())
+		}
+
+		// then
+		if !bytes.Equal(recovered.Bytes(), message) {
+			t.Errorf("RSAUnpaddedMessageRecovery(...) (%s) = %q, expected %q", name, recovered.Bytes(), message)
+		}
+	}
+}
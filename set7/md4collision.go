@@ -0,0 +1,200 @@
+package cryptochallenges
+
+import (
+	"crypto/rand"
+	"math/bits"
+
+	"github.com/ka3de/go-cryptochallenges/md4"
+)
+
+// Wang et al. MD4 collision differential: M' = M + (2^31 at m1, 2^31 - 2^28
+// at m2, - 2^16 at m12)
+var md4CollisionDifferential = [16]uint32{
+	1:  1 << 31,
+	2:  1<<31 - 1<<28,
+	12: 1<<32 - 1<<16,
+}
+
+type md4ConditionKind int
+
+const (
+	md4Zero md4ConditionKind = iota
+	md4One
+	md4Equal
+	md4NotEqual
+)
+
+// md4Condition - sufficient condition on one bit of a chaining value, equal
+// and not equal conditions refer to the same bit of a previous chaining value
+type md4Condition struct {
+	bit  uint
+	kind md4ConditionKind
+	ref  int
+}
+
+// md4A, md4D, md4C, md4B - index in md4.Chain of the a, d, c and b chaining
+// values computed in the i-th group of steps (i = 0 being the input state)
+func md4A(i int) int { return 4 * i }
+func md4D(i int) int { return 4*i + 1 }
+func md4C(i int) int { return 4*i + 2 }
+func md4B(i int) int { return 4*i + 3 }
+
+// bit positions are numbered 1 to 32 as in the paper
+func bitZero(bit uint) md4Condition           { return md4Condition{bit - 1, md4Zero, 0} }
+func bitOne(bit uint) md4Condition            { return md4Condition{bit - 1, md4One, 0} }
+func bitEqual(bit uint, ref int) md4Condition { return md4Condition{bit - 1, md4Equal, ref} }
+func bitNotEqual(bit uint, ref int) md4Condition {
+	return md4Condition{bit - 1, md4NotEqual, ref}
+}
+
+// md4Conditions - Wang et al. sufficient conditions, by chaining value index
+var md4Conditions = map[int][]md4Condition{
+	// round 1
+	md4A(1): {bitEqual(7, md4B(0))},
+	md4D(1): {bitZero(7), bitEqual(8, md4A(1)), bitEqual(11, md4A(1))},
+	md4C(1): {bitOne(7), bitOne(8), bitZero(11), bitEqual(26, md4D(1))},
+	md4B(1): {bitOne(7), bitZero(8), bitZero(11), bitZero(26)},
+	md4A(2): {bitOne(8), bitOne(11), bitZero(26), bitEqual(14, md4B(1))},
+	md4D(2): {bitZero(14), bitEqual(19, md4A(2)), bitEqual(20, md4A(2)), bitEqual(21, md4A(2)),
+		bitEqual(22, md4A(2)), bitOne(26)},
+	md4C(2): {bitEqual(13, md4D(2)), bitZero(14), bitEqual(15, md4D(2)), bitZero(19), bitZero(20),
+		bitOne(21), bitZero(22)},
+	md4B(2): {bitOne(13), bitOne(14), bitZero(15), bitEqual(17, md4C(2)), bitZero(19), bitZero(20),
+		bitZero(21), bitZero(22)},
+	md4A(3): {bitOne(13), bitOne(14), bitOne(15), bitZero(17), bitZero(19), bitZero(20), bitZero(21),
+		bitOne(22), bitEqual(23, md4B(2)), bitEqual(26, md4B(2))},
+	md4D(3): {bitOne(13), bitOne(14), bitOne(15), bitZero(17), bitZero(20), bitOne(21), bitOne(22),
+		bitZero(23), bitOne(26), bitEqual(30, md4A(3))},
+	md4C(3): {bitOne(17), bitZero(20), bitZero(21), bitZero(22), bitZero(23), bitZero(26), bitOne(30),
+		bitEqual(32, md4D(3))},
+	md4B(3): {bitZero(20), bitOne(21), bitOne(22), bitEqual(23, md4C(3)), bitOne(26), bitZero(30),
+		bitZero(32)},
+	md4A(4): {bitZero(23), bitZero(26), bitEqual(27, md4B(3)), bitEqual(29, md4B(3)), bitOne(30),
+		bitZero(32)},
+	md4D(4): {bitZero(23), bitZero(26), bitOne(27), bitOne(29), bitZero(30), bitOne(32)},
+	md4C(4): {bitEqual(19, md4D(4)), bitOne(23), bitOne(26), bitZero(27), bitZero(29), bitZero(30)},
+	md4B(4): {bitZero(19), bitOne(26), bitOne(27), bitOne(29), bitZero(30)},
+
+	// round 2
+	md4A(5): {bitEqual(19, md4C(4)), bitOne(26), bitZero(27), bitOne(29), bitOne(32)},
+	md4D(5): {bitEqual(19, md4A(5)), bitEqual(26, md4B(4)), bitEqual(27, md4B(4)),
+		bitEqual(29, md4B(4)), bitEqual(32, md4B(4))},
+	md4C(5): {bitEqual(26, md4D(5)), bitEqual(27, md4D(5)), bitEqual(29, md4D(5)),
+		bitEqual(30, md4D(5)), bitEqual(32, md4D(5))},
+	md4B(5): {bitEqual(29, md4C(5)), bitOne(30), bitZero(32)},
+	md4A(6): {bitOne(29), bitOne(32)},
+	md4D(6): {bitEqual(29, md4B(5))},
+	md4C(6): {bitEqual(29, md4D(6)), bitNotEqual(30, md4D(6)), bitNotEqual(32, md4D(6))},
+}
+
+// holds - returns whether the condition holds for value
+func (c md4Condition) holds(value uint32, chain []uint32) bool {
+	bit := value >> c.bit & 1
+	switch c.kind {
+	case md4Zero:
+		return bit == 0
+	case md4One:
+		return bit == 1
+	case md4Equal:
+		return bit == chain[c.ref]>>c.bit&1
+	default:
+		return bit != chain[c.ref]>>c.bit&1
+	}
+}
+
+// apply - returns value modified so the condition holds
+func (c md4Condition) apply(value uint32, chain []uint32) uint32 {
+	if c.holds(value, chain) {
+		return value
+	}
+	return value ^ 1<<c.bit
+}
+
+// md4Round1Word - returns the message word making round 1 step compute the
+// chaining value chain[step+4] from the previous ones
+func md4Round1Word(chain []uint32, step int) uint32 {
+	return bits.RotateLeft32(chain[step+4], -md4.StepShift(step)) -
+		chain[step] - md4.F(chain[step+3], chain[step+2], chain[step+1])
+}
+
+// md4SingleStepModification - modifies block so every round 1 condition holds
+func md4SingleStepModification(block *[16]uint32) {
+	chain := md4.Chain(md4.InitialState, *block)
+	for step := 0; step < 16; step++ {
+		value := bits.RotateLeft32(chain[step]+
+			md4.F(chain[step+3], chain[step+2], chain[step+1])+block[step], md4.StepShift(step))
+		for _, condition := range md4Conditions[step+4] {
+			value = condition.apply(value, chain[:])
+		}
+		chain[step+4] = value
+		block[step] = md4Round1Word(chain[:], step)
+	}
+}
+
+// md4MultiStepModification - corrects the round 2 conditions on the chaining
+// value computed by step (16 or 17) by flipping a bit of the round 1 value
+// using the same message word, and fixing the next 4 message words so the
+// rest of round 1 is left unchanged
+func md4MultiStepModification(block *[16]uint32, step int) {
+	// round 1 step using the same message word as step
+	round1Step := md4.StepWord(step)
+	// a bit flip in the round 1 value is a bit flip at this offset in step
+	offset := uint(md4.StepShift(step) - md4.StepShift(round1Step))
+
+	chain := md4.Chain(md4.InitialState, *block)
+	for _, condition := range md4Conditions[step+4] {
+		if condition.holds(chain[step+4], chain[:]) {
+			continue
+		}
+
+		chain[round1Step+4] ^= bits.RotateLeft32(1<<condition.bit, -int(offset))
+		for s := round1Step; s <= round1Step+4; s++ {
+			block[s] = md4Round1Word(chain[:], s)
+		}
+		chain = md4.Chain(md4.InitialState, *block)
+	}
+}
+
+// md4ConditionsHold - returns whether every sufficient condition holds for
+// the chaining values of block
+func md4ConditionsHold(block [16]uint32) bool {
+	chain := md4.Chain(md4.InitialState, block)
+	for index, conditions := range md4Conditions {
+		for _, condition := range conditions {
+			if !condition.holds(chain[index], chain[:]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MD4Collision - searches for a pair of distinct 64 bytes messages with the
+// same MD4 hash using Wang et al. attack: round 1 conditions are met with
+// single-step message modification, the first round 2 conditions with
+// multi-step modification and the remaining ones are left to chance. Returns
+// both messages and the number of candidate pairs tried
+func MD4Collision() ([]byte, []byte, int, error) {
+	randomBytes := make([]byte, md4.BlockSize)
+	for attempts := 1; ; attempts++ {
+		if _, err := rand.Read(randomBytes); err != nil {
+			return nil, nil, 0, err
+		}
+		block := md4.DecodeBlock(randomBytes)
+
+		md4SingleStepModification(&block)
+		md4MultiStepModification(&block, 16)
+		md4MultiStepModification(&block, 17)
+		if !md4ConditionsHold(block) {
+			continue
+		}
+
+		var collidingBlock [16]uint32
+		for i := range block {
+			collidingBlock[i] = block[i] + md4CollisionDifferential[i]
+		}
+		if md4.Compress(md4.InitialState, block) == md4.Compress(md4.InitialState, collidingBlock) {
+			return md4.EncodeBlock(block), md4.EncodeBlock(collidingBlock), attempts, nil
+		}
+	}
+}
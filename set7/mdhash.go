@@ -0,0 +1,117 @@
+package cryptochallenges
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"sync/atomic"
+)
+
+// MDBlockSize - block size in bytes of the toy Merkle-Damgård hash
+const MDBlockSize = aes.BlockSize
+
+// MDHash - deliberately weak Merkle-Damgård hash, its compression function
+// encrypts the message block with AES keyed by the (zero padded) state and
+// truncates the result to a state of a few bits
+type MDHash struct {
+	stateBits    int
+	initialState []byte
+
+	// number of compression function calls, updated atomically
+	calls uint64
+}
+
+// NewMDHash - returns a new toy hash with a state of stateBits bits (8 to 64)
+// and the given initial state
+func NewMDHash(stateBits int, initialState []byte) (*MDHash, error) {
+	if stateBits < 8 || stateBits > 64 {
+		return nil, errors.New("state size must be between 8 and 64 bits")
+	}
+
+	h := &MDHash{stateBits: stateBits}
+	if len(initialState) != h.StateSize() {
+		return nil, errors.New("invalid initial state size")
+	}
+	h.initialState = h.truncate(initialState)
+
+	return h, nil
+}
+
+// StateSize - returns the state size in bytes
+func (h *MDHash) StateSize() int {
+	return (h.stateBits + 7) / 8
+}
+
+// StateBits - returns the state size in bits
+func (h *MDHash) StateBits() int {
+	return h.stateBits
+}
+
+// InitialState - returns a copy of the hash initial state
+func (h *MDHash) InitialState() []byte {
+	return append([]byte{}, h.initialState...)
+}
+
+// Calls - returns the number of compression function calls made so far
+func (h *MDHash) Calls() uint64 {
+	return atomic.LoadUint64(&h.calls)
+}
+
+// truncate - returns the first state size bytes of data, with the bits
+// beyond the state size cleared
+func (h *MDHash) truncate(data []byte) []byte {
+	state := append([]byte{}, data[:h.StateSize()]...)
+	if extraBits := uint(8*h.StateSize() - h.stateBits); extraBits > 0 {
+		state[len(state)-1] &= 0xff << extraBits
+	}
+	return state
+}
+
+// Compress - compression function, returns the state after processing block
+func (h *MDHash) Compress(state, block []byte) []byte {
+	atomic.AddUint64(&h.calls, 1)
+
+	key := make([]byte, aes.BlockSize)
+	copy(key, state)
+	aesCipher, _ := aes.NewCipher(key) // key size is always valid
+
+	output := make([]byte, aes.BlockSize)
+	aesCipher.Encrypt(output, block)
+	return h.truncate(output)
+}
+
+// Iterate - returns the state after processing the block aligned blocks
+// starting from state, no padding is applied
+func (h *MDHash) Iterate(state, blocks []byte) []byte {
+	for len(blocks) >= MDBlockSize {
+		state = h.Compress(state, blocks[:MDBlockSize])
+		blocks = blocks[MDBlockSize:]
+	}
+	return state
+}
+
+// Sum - returns the hash of message, padded with MD strengthening
+func (h *MDHash) Sum(message []byte) []byte {
+	return h.Iterate(h.initialState, MDPad(message, len(message)))
+}
+
+// MDPad - pads message with 0x80, zeros and the big-endian bit length of
+// the totalLength bytes long message it belongs to
+func MDPad(message []byte, totalLength int) []byte {
+	padded := append(append([]byte{}, message...), 0x80)
+	for len(padded)%MDBlockSize != MDBlockSize-8 {
+		padded = append(padded, 0)
+	}
+
+	length := make([]byte, 8)
+	binary.BigEndian.PutUint64(length, uint64(totalLength)*8)
+	return append(padded, length...)
+}
+
+// randomBlock - returns a random message block
+func randomBlock() ([]byte, error) {
+	block := make([]byte, MDBlockSize)
+	_, err := rand.Read(block)
+	return block, err
+}
@@ -0,0 +1,154 @@
+package cryptochallenges
+
+import (
+	"crypto/rand"
+	"errors"
+	"sync"
+
+	"github.com/ka3de/go-cryptochallenges/rc4"
+)
+
+// RC4 keystream bytes 16 and 32 are biased towards 240 and 224 respectively
+var rc4Biases = []struct {
+	position int
+	value    byte
+}{
+	{15, 240},
+	{31, 224},
+}
+
+// RC4Oracle - returns the encryption of request || secret
+type RC4Oracle func(request []byte) ([]byte, error)
+
+// NewRC4CookieOracle - returns an oracle encrypting request || cookie with
+// RC4 under a fresh random 128 bits key on every call
+func NewRC4CookieOracle(cookie []byte) RC4Oracle {
+	return func(request []byte) ([]byte, error) {
+		key := make([]byte, 16)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+
+		rc4Cipher, err := rc4.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		plaintext := append(append([]byte{}, request...), cookie...)
+		rc4Cipher.XORKeyStream(plaintext, plaintext)
+		return plaintext, nil
+	}
+}
+
+// rc4BiasCounts - returns the distribution of the ciphertext bytes at the
+// biased positions over queries encryptions of request, split among workers
+func rc4BiasCounts(oracle RC4Oracle, request []byte, queries, workers int) ([][256]int, error) {
+	counts := make([][256]int, len(rc4Biases))
+	errs := make(chan error, workers)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		share := queries / workers
+		if w < queries%workers {
+			share++
+		}
+
+		wg.Add(1)
+		go func(queries int) {
+			defer wg.Done()
+
+			// tally locally, merge once at the end
+			localCounts := make([][256]int, len(rc4Biases))
+			for q := 0; q < queries; q++ {
+				ciphertext, err := oracle(request)
+				if err != nil {
+					errs <- err
+					return
+				}
+				for i, bias := range rc4Biases {
+					if bias.position < len(ciphertext) {
+						localCounts[i][ciphertext[bias.position]]++
+					}
+				}
+			}
+
+			mutex.Lock()
+			for i := range counts {
+				for b, count := range localCounts[i] {
+					counts[i][b] += count
+				}
+			}
+			mutex.Unlock()
+		}(share)
+	}
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return nil, err
+	default:
+		return counts, nil
+	}
+}
+
+// RC4BiasAttack - recovers the secret encrypted by the oracle exploiting the
+// RC4 single-byte biases at positions 16 and 32: each secret byte is aimed
+// at both positions with padding requests, and its value is the one most
+// voted by the ciphertext bytes there XOR the biased keystream values. Every
+// padding length is queried queriesPerPadding times (around 2^24 for full
+// accuracy) using workers goroutines. Requests only push the secret further,
+// so it can be at most 32 bytes long for all its bytes to reach a biased
+// position
+func RC4BiasAttack(oracle RC4Oracle, queriesPerPadding, workers int) ([]byte, error) {
+	ciphertext, err := oracle(nil)
+	if err != nil {
+		return nil, err
+	}
+	lastPosition := rc4Biases[len(rc4Biases)-1].position
+	if len(ciphertext) > lastPosition+1 {
+		return nil, errors.New("secret longer than the biased positions")
+	}
+	if workers < 1 {
+		return nil, errors.New("at least one worker is needed")
+	}
+
+	votes := make([][256]int, len(ciphertext))
+	for padding := 0; padding <= lastPosition; padding++ {
+		// skip paddings that do not aim any secret byte at a biased position
+		aimed := false
+		for _, bias := range rc4Biases {
+			index := bias.position - padding
+			aimed = aimed || (index >= 0 && index < len(votes))
+		}
+		if !aimed {
+			continue
+		}
+
+		counts, err := rc4BiasCounts(oracle, make([]byte, padding), queriesPerPadding, workers)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, bias := range rc4Biases {
+			index := bias.position - padding
+			if index < 0 || index >= len(votes) {
+				continue
+			}
+			for c, count := range counts[i] {
+				votes[index][byte(c)^bias.value] += count
+			}
+		}
+	}
+
+	secret := make([]byte, len(votes))
+	for index := range votes {
+		for b, count := range votes[index] {
+			if count > votes[index][secret[index]] {
+				secret[index] = byte(b)
+			}
+		}
+	}
+
+	return secret, nil
+}
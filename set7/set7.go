@@ -0,0 +1,976 @@
+package cryptochallenges
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ka3de/go-cryptochallenges/tools"
+
+	set1 "github.com/ka3de/go-cryptochallenges/set1"
+	set2 "github.com/ka3de/go-cryptochallenges/set2"
+)
+
+// CBCMAC - computes the CBC-MAC of the (PKCS#7 padded) message, the last
+// block of its CBC encryption under the given IV
+func CBCMAC(message, iv []byte, blockCipher cipher.Block) ([]byte, error) {
+	blockSize := blockCipher.BlockSize()
+
+	ciphertext, err := set2.EncryptCBCWithIV(message, iv, blockCipher, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return ciphertext[len(ciphertext)-blockSize:], nil
+}
+
+// CBCMACZeroIV - computes the CBC-MAC of the message with a fixed all zeros IV
+func CBCMACZeroIV(message []byte, blockCipher cipher.Block) ([]byte, error) {
+	return CBCMAC(message, make([]byte, blockCipher.BlockSize()), blockCipher)
+}
+
+// cbcChainState - returns the CBC chaining value after processing the
+// block aligned message, without any padding
+func cbcChainState(message, iv []byte, blockCipher cipher.Block) ([]byte, error) {
+	blockSize := blockCipher.BlockSize()
+	if len(message)%blockSize != 0 {
+		return nil, errors.New("message is not block aligned")
+	}
+	if len(message) == 0 {
+		return iv, nil
+	}
+
+	ciphertext, err := set2.EncryptCBCWithIV(message, iv, blockCipher, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return ciphertext[len(message)-blockSize : len(message)], nil
+}
+
+// Transfer - money transfer between two accounts
+type Transfer struct {
+	From   int
+	To     int
+	Amount int
+}
+
+// MoneyTransferAPI - toy bank API whose web client and server share a key
+// to authenticate transfer requests with CBC-MAC
+type MoneyTransferAPI struct {
+	blockCipher cipher.Block
+}
+
+// NewMoneyTransferAPI - returns a new transfer API with a random shared key
+func NewMoneyTransferAPI() (*MoneyTransferAPI, error) {
+	key := make([]byte, set1.AESBlockSize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	blockCipher, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MoneyTransferAPI{blockCipher: blockCipher}, nil
+}
+
+// NewTransferRequest - client side, returns the request for a single
+// transfer as message || IV || MAC with a random IV
+func (api *MoneyTransferAPI) NewTransferRequest(transfer Transfer) ([]byte, error) {
+	message := []byte(fmt.Sprintf("to=%d&amount=%d&from=%d", transfer.To, transfer.Amount, transfer.From))
+
+	iv := make([]byte, set1.AESBlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	mac, err := CBCMAC(message, iv, api.blockCipher)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append(message, iv...), mac...), nil
+}
+
+// splitTransferRequest - splits a message || IV || MAC request
+func splitTransferRequest(request []byte) ([]byte, []byte, []byte, error) {
+	if len(request) < 2*set1.AESBlockSize {
+		return nil, nil, nil, errors.New("invalid request length")
+	}
+
+	macStart := len(request) - set1.AESBlockSize
+	ivStart := macStart - set1.AESBlockSize
+
+	return request[:ivStart], request[ivStart:macStart], request[macStart:], nil
+}
+
+// parseTransfer - parses a to=..&amount=..&from=.. transfer message
+func parseTransfer(message string) (Transfer, error) {
+	values := make(map[string]int)
+	for _, attribute := range strings.Split(message, "&") {
+		components := strings.SplitN(attribute, "=", 2)
+		if len(components) != 2 {
+			return Transfer{}, errors.New("invalid transfer attribute " + attribute)
+		}
+
+		value, err := strconv.Atoi(components[1])
+		if err != nil {
+			return Transfer{}, err
+		}
+		values[components[0]] = value
+	}
+
+	return Transfer{From: values["from"], To: values["to"], Amount: values["amount"]}, nil
+}
+
+// ProcessTransferRequest - server side, checks the request MAC and returns the transfer
+func (api *MoneyTransferAPI) ProcessTransferRequest(request []byte) (Transfer, error) {
+	message, iv, mac, err := splitTransferRequest(request)
+	if err != nil {
+		return Transfer{}, err
+	}
+
+	expectedMAC, err := CBCMAC(message, iv, api.blockCipher)
+	if err != nil {
+		return Transfer{}, err
+	}
+	if !bytes.Equal(mac, expectedMAC) {
+		return Transfer{}, errors.New("invalid request MAC")
+	}
+
+	return parseTransfer(string(message))
+}
+
+// ForgeTransferRequest - changes the recipient and amount of a captured transfer
+// request without knowing the key. The new values must keep the message
+// length and lie within its first block, whose changes are cancelled out
+// by flipping the same bits in the attacker controlled IV
+func ForgeTransferRequest(request []byte, to, amount int) ([]byte, error) {
+	message, iv, mac, err := splitTransferRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := parseTransfer(string(message))
+	if err != nil {
+		return nil, err
+	}
+
+	forgedMessage := []byte(fmt.Sprintf("to=%d&amount=%d&from=%d", to, amount, transfer.From))
+	if len(forgedMessage) != len(message) {
+		return nil, errors.New("forged message length does not match")
+	}
+	if !bytes.Equal(forgedMessage[set1.AESBlockSize:], message[set1.AESBlockSize:]) {
+		return nil, errors.New("forged values do not fit in the first block")
+	}
+
+	// IV' = IV ^ M[0] ^ M'[0] keeps the first block CBC input unchanged
+	blockDiff, err := set1.Xor(message[:set1.AESBlockSize], forgedMessage[:set1.AESBlockSize])
+	if err != nil {
+		return nil, err
+	}
+	forgedIV, err := set1.Xor(iv, blockDiff)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append(forgedMessage, forgedIV...), mac...), nil
+}
+
+// NewMultiTransferRequest - client side, returns the request for a list of
+// transfers from the same account as message || MAC with a fixed zero IV
+func (api *MoneyTransferAPI) NewMultiTransferRequest(from int, transfers []Transfer) ([]byte, error) {
+	var txList []string
+	for _, transfer := range transfers {
+		txList = append(txList, fmt.Sprintf("%d:%d", transfer.To, transfer.Amount))
+	}
+	message := []byte(fmt.Sprintf("from=%d&tx_list=%s", from, strings.Join(txList, ";")))
+
+	mac, err := CBCMACZeroIV(message, api.blockCipher)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(message, mac...), nil
+}
+
+// ProcessMultiTransferRequest - server side, checks the request MAC and returns
+// the transfers in it. Malformed transactions in the list are skipped
+func (api *MoneyTransferAPI) ProcessMultiTransferRequest(request []byte) ([]Transfer, error) {
+	if len(request) < set1.AESBlockSize {
+		return nil, errors.New("invalid request length")
+	}
+	message, mac := request[:len(request)-set1.AESBlockSize], request[len(request)-set1.AESBlockSize:]
+
+	expectedMAC, err := CBCMACZeroIV(message, api.blockCipher)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(mac, expectedMAC) {
+		return nil, errors.New("invalid request MAC")
+	}
+
+	const txListKey = "&tx_list="
+	txListStart := bytes.Index(message, []byte(txListKey))
+	if !bytes.HasPrefix(message, []byte("from=")) || txListStart < 0 {
+		return nil, errors.New("invalid multi transfer message")
+	}
+
+	from, err := strconv.Atoi(string(message[len("from="):txListStart]))
+	if err != nil {
+		return nil, err
+	}
+
+	var transfers []Transfer
+	for _, tx := range strings.Split(string(message[txListStart+len(txListKey):]), ";") {
+		components := strings.Split(tx, ":")
+		if len(components) != 2 {
+			continue
+		}
+
+		to, errTo := strconv.Atoi(components[0])
+		amount, errAmount := strconv.Atoi(components[1])
+		if errTo != nil || errAmount != nil {
+			continue
+		}
+		transfers = append(transfers, Transfer{From: from, To: to, Amount: amount})
+	}
+
+	return transfers, nil
+}
+
+// CBCMACConcatenationForgery - given a message and its fixed IV CBC-MAC, and a
+// second message, returns a message starting with the first one whose
+// CBC-MAC is the one of the second message:
+// pad(message1) || (message2[0] ^ mac1) || message2[1:]
+func CBCMACConcatenationForgery(message1, mac1, message2 []byte) ([]byte, error) {
+	blockSize := len(mac1)
+	if len(message2) < blockSize {
+		return nil, errors.New("second message must be at least one block long")
+	}
+
+	glueBlock, err := set1.Xor(message2[:blockSize], mac1)
+	if err != nil {
+		return nil, err
+	}
+
+	forged := tools.ApplyPkcs7Padding(append([]byte{}, message1...), blockSize)
+	forged = append(forged, glueBlock...)
+	return append(forged, message2[blockSize:]...), nil
+}
+
+// ExtendMultiTransferRequest - appends the transactions of the attacker own
+// signed request to a captured victim request, the forged request carries
+// the attacker request MAC
+func ExtendMultiTransferRequest(victimRequest, attackerRequest []byte) ([]byte, error) {
+	if len(victimRequest) < set1.AESBlockSize || len(attackerRequest) < set1.AESBlockSize {
+		return nil, errors.New("invalid request length")
+	}
+
+	victimMessage := victimRequest[:len(victimRequest)-set1.AESBlockSize]
+	victimMAC := victimRequest[len(victimRequest)-set1.AESBlockSize:]
+	attackerMessage := attackerRequest[:len(attackerRequest)-set1.AESBlockSize]
+	attackerMAC := attackerRequest[len(attackerRequest)-set1.AESBlockSize:]
+
+	forgedMessage, err := CBCMACConcatenationForgery(victimMessage, victimMAC, attackerMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(forgedMessage, attackerMAC...), nil
+}
+
+// CBCMACHash - CBC-MAC used as a hash function, with a publicly known key and zero IV
+func CBCMACHash(message, key []byte) ([]byte, error) {
+	blockCipher, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return CBCMACZeroIV(message, blockCipher)
+}
+
+// ForgeJavaScriptCBCMACCollision - returns a JavaScript snippet that runs
+// payload and has the same CBC-MAC hash as original. The payload is followed
+// by a line comment, then a glue block that sets the chaining value so the
+// original snippet blocks produce the same final MAC
+func ForgeJavaScriptCBCMACCollision(original, payload, key []byte) ([]byte, error) {
+	blockCipher, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	blockSize := blockCipher.BlockSize()
+	if len(original) < blockSize {
+		return nil, errors.New("original snippet must be at least one block long")
+	}
+
+	// the glue block is random-looking, try different comment filler blocks
+	// after the payload until it has no line terminator ending the comment
+	prefix := append(append([]byte{}, payload...), "//"...)
+	for len(prefix)%blockSize != 0 {
+		prefix = append(prefix, ' ')
+	}
+
+	for attempt := 0; attempt < 1024; attempt++ {
+		fillerBlock := []byte(fmt.Sprintf("%*d", blockSize, attempt))
+		commentedPrefix := append(append([]byte{}, prefix...), fillerBlock...)
+
+		state, err := cbcChainState(commentedPrefix, make([]byte, blockSize), blockCipher)
+		if err != nil {
+			return nil, err
+		}
+
+		glueBlock, err := set1.Xor(state, original[:blockSize])
+		if err != nil {
+			return nil, err
+		}
+
+		if !bytes.ContainsAny(glueBlock, "\n\r") && !bytes.Contains(glueBlock, []byte("\u2028")) &&
+			!bytes.Contains(glueBlock, []byte("\u2029")) {
+			forged := append(commentedPrefix, glueBlock...)
+			return append(forged, original[blockSize:]...), nil
+		}
+	}
+
+	return nil, errors.New("could not find a glue block without line terminators")
+}
+
+const (
+	compressionCookiePrefix = "sessionid="
+
+	// base64 alphabet plus the newline that ends the cookie header
+	compressionCookieAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/=\n"
+
+	// characters out of the cookie alphabet used to align the compressed length
+	compressionFillerChars = "!@#$%^&*()-[]{}|;:,.<>?`~_"
+
+	// upper bound of the recovered cookie length
+	compressionMaxCookieSize = 256
+)
+
+// CompressionOracle - returns the length of the encrypted and compressed
+// request embedding the secret session cookie and the attacker data
+type CompressionOracle func(data []byte) (int, error)
+
+// formatCompressionRequest - builds the HTTP-like request sent by the victim
+func formatCompressionRequest(sessionID string, data []byte) []byte {
+	return []byte(fmt.Sprintf("POST / HTTP/1.1\nHost: hapless.com\nCookie: %s%s\nContent-Length: %d\n%s",
+		compressionCookiePrefix, sessionID, len(data), data))
+}
+
+// compress - DEFLATE compresses data, the best compression level is used
+// since the faster ones do not search matches hard enough to leak the cookie
+func compress(data []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+
+	writer, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return compressed.Bytes(), nil
+}
+
+// newAESCipher - returns an AES cipher under a fresh random key
+func newAESCipher() (cipher.Block, error) {
+	key := make([]byte, set1.AESBlockSize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return aes.NewCipher(key)
+}
+
+// NewStreamCompressionOracle - returns a compression oracle encrypting the
+// compressed request with AES-CTR under a fresh key and nonce each time,
+// so the ciphertext length is the compressed length
+func NewStreamCompressionOracle(sessionID string) CompressionOracle {
+	return func(data []byte) (int, error) {
+		compressed, err := compress(formatCompressionRequest(sessionID, data))
+		if err != nil {
+			return 0, err
+		}
+
+		blockCipher, err := newAESCipher()
+		if err != nil {
+			return 0, err
+		}
+		iv := make([]byte, set1.AESBlockSize)
+		if _, err := rand.Read(iv); err != nil {
+			return 0, err
+		}
+
+		ciphertext := make([]byte, len(compressed))
+		cipher.NewCTR(blockCipher, iv).XORKeyStream(ciphertext, compressed)
+		return len(ciphertext), nil
+	}
+}
+
+// NewCBCCompressionOracle - returns a compression oracle encrypting the
+// compressed request with AES-CBC under a fresh key and IV each time,
+// padding hides length differences smaller than a block
+func NewCBCCompressionOracle(sessionID string) CompressionOracle {
+	return func(data []byte) (int, error) {
+		compressed, err := compress(formatCompressionRequest(sessionID, data))
+		if err != nil {
+			return 0, err
+		}
+
+		blockCipher, err := newAESCipher()
+		if err != nil {
+			return 0, err
+		}
+
+		_, ciphertext, err := set2.EncryptCBC(compressed, blockCipher, set1.AESBlockSize)
+		if err != nil {
+			return 0, err
+		}
+		return len(ciphertext), nil
+	}
+}
+
+// compressionAlignmentFiller - returns the shortest filler of incompressible
+// characters that makes a wrong guess just overflow into a new length unit
+// (a byte or a block), so that the few bits saved by a right guess show up
+func compressionAlignmentFiller(oracle CompressionOracle, guessPrefix string) (string, error) {
+	wrongGuess := guessPrefix + "~"
+
+	baseLength, err := oracle([]byte(wrongGuess))
+	if err != nil {
+		return "", err
+	}
+
+	for size := 1; size <= len(compressionFillerChars); size++ {
+		length, err := oracle([]byte(compressionFillerChars[:size] + wrongGuess))
+		if err != nil {
+			return "", err
+		}
+		if length > baseLength {
+			return compressionFillerChars[:size], nil
+		}
+	}
+
+	return "", nil
+}
+
+// bestCompressionGuesses - returns the guesses that lead to the shortest length
+func bestCompressionGuesses(oracle CompressionOracle, filler, guessPrefix string,
+	guesses []string) ([]string, error) {
+
+	bestLength := -1
+	var bestGuesses []string
+
+	for _, guess := range guesses {
+		length, err := oracle([]byte(filler + guessPrefix + guess))
+		if err != nil {
+			return nil, err
+		}
+
+		if bestLength < 0 || length < bestLength {
+			bestLength = length
+			bestGuesses = []string{guess}
+		} else if length == bestLength {
+			bestGuesses = append(bestGuesses, guess)
+		}
+	}
+
+	return bestGuesses, nil
+}
+
+// extendGuesses - returns every guess followed by each alphabet character
+func extendGuesses(guesses []string) []string {
+	var extended []string
+	for _, guess := range guesses {
+		for _, char := range compressionCookieAlphabet {
+			extended = append(extended, guess+string(char))
+		}
+	}
+	return extended
+}
+
+// CompressionRatioAttack - recovers the session cookie from a compression
+// oracle one character at a time, the right character extends the match
+// against the cookie and compresses better than the rest. Ties are broken
+// by guessing one more character, and the length is aligned with filler
+// characters so that the difference is not hidden by block padding
+func CompressionRatioAttack(oracle CompressionOracle) (string, error) {
+	sessionID := ""
+
+	for len(sessionID) < compressionMaxCookieSize {
+		guessPrefix := compressionCookiePrefix + sessionID
+
+		filler, err := compressionAlignmentFiller(oracle, guessPrefix)
+		if err != nil {
+			return "", err
+		}
+
+		guesses, err := bestCompressionGuesses(oracle, filler, guessPrefix, extendGuesses([]string{""}))
+		if err != nil {
+			return "", err
+		}
+
+		if len(guesses) > 1 {
+			guesses, err = bestCompressionGuesses(oracle, filler, guessPrefix, extendGuesses(guesses))
+			if err != nil {
+				return "", err
+			}
+		}
+
+		nextChar := guesses[0][:1]
+		if nextChar == "\n" {
+			return sessionID, nil
+		}
+		sessionID += nextChar
+	}
+
+	return "", errors.New("session cookie end not found")
+}
+
+// FindMDCollision - birthday search for two distinct blocks that lead to the
+// same state when compressed from state, returns both blocks and that state
+func FindMDCollision(h *MDHash, state []byte) ([]byte, []byte, []byte, error) {
+	seen := make(map[string][]byte)
+
+	for {
+		block, err := randomBlock()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		nextState := h.Compress(state, block)
+		if otherBlock, ok := seen[string(nextState)]; ok && !bytes.Equal(otherBlock, block) {
+			return otherBlock, block, nextState, nil
+		}
+		seen[string(nextState)] = block
+	}
+}
+
+// JouxMulticollision - chains n block collisions starting from the hash
+// initial state, any choice of one block out of each pair gives one of 2^n
+// messages with the same hash. Returns the pairs and the final state
+func JouxMulticollision(h *MDHash, n int) ([][2][]byte, []byte, error) {
+	state := h.InitialState()
+	collisions := make([][2][]byte, 0, n)
+
+	for i := 0; i < n; i++ {
+		block1, block2, nextState, err := FindMDCollision(h, state)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		collisions = append(collisions, [2][]byte{block1, block2})
+		state = nextState
+	}
+
+	return collisions, state, nil
+}
+
+// MulticollisionMessages - returns the 2^n messages built from n collision pairs
+func MulticollisionMessages(collisions [][2][]byte) [][]byte {
+	messages := [][]byte{{}}
+
+	for _, collision := range collisions {
+		extended := make([][]byte, 0, 2*len(messages))
+		for _, message := range messages {
+			for _, block := range collision {
+				extended = append(extended, append(append([]byte{}, message...), block...))
+			}
+		}
+		messages = extended
+	}
+
+	return messages
+}
+
+// ConcatenatedHashCollision - finds two messages colliding on both hashes,
+// that is on cheap(m) || expensive(m). A Joux multicollision of 2^(b/2)
+// messages in the cheap hash, b being the expensive hash state size, is
+// likely to contain a birthday collision in the expensive one, otherwise
+// it is doubled with one more cheap collision
+func ConcatenatedHashCollision(cheap, expensive *MDHash) ([]byte, []byte, error) {
+	collisions, state, err := JouxMulticollision(cheap, expensive.StateBits()/2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for {
+		seen := make(map[string][]byte)
+		for _, message := range MulticollisionMessages(collisions) {
+			digest := expensive.Sum(message)
+			if otherMessage, ok := seen[string(digest)]; ok {
+				return otherMessage, message, nil
+			}
+			seen[string(digest)] = message
+		}
+
+		block1, block2, nextState, err := FindMDCollision(cheap, state)
+		if err != nil {
+			return nil, nil, err
+		}
+		collisions = append(collisions, [2][]byte{block1, block2})
+		state = nextState
+	}
+}
+
+// findMDCollisionFromStates - birthday search for a block from state1 and a
+// block from state2 leading to the same state, returns both blocks and that state
+func findMDCollisionFromStates(h *MDHash, state1, state2 []byte) ([]byte, []byte, []byte, error) {
+	seen1 := make(map[string][]byte)
+	seen2 := make(map[string][]byte)
+
+	for {
+		block1, err := randomBlock()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		block2, err := randomBlock()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		next1 := h.Compress(state1, block1)
+		next2 := h.Compress(state2, block2)
+		seen1[string(next1)] = block1
+		seen2[string(next2)] = block2
+
+		if other2, ok := seen2[string(next1)]; ok {
+			return block1, other2, next1, nil
+		}
+		if other1, ok := seen1[string(next2)]; ok {
+			return other1, block2, next2, nil
+		}
+	}
+}
+
+// ExpandableMessage - set of k (short, long) colliding pieces, picking one of
+// each gives a message of any length between k and k + 2^k - 1 blocks,
+// all of them leading to the same final state
+type ExpandableMessage struct {
+	pieces     [][2][]byte
+	finalState []byte
+}
+
+// NewExpandableMessage - builds a (k, k + 2^k - 1) expandable message from
+// the hash initial state, piece i being a collision between a single block
+// and 2^(k-1-i) dummy blocks followed by another block
+func NewExpandableMessage(h *MDHash, k int) (*ExpandableMessage, error) {
+	state := h.InitialState()
+	dummyBlock := make([]byte, MDBlockSize)
+	pieces := make([][2][]byte, 0, k)
+
+	for i := k - 1; i >= 0; i-- {
+		dummyBlocks := bytes.Repeat(dummyBlock, 1<<uint(i))
+		dummyState := h.Iterate(state, dummyBlocks)
+
+		shortBlock, longBlock, nextState, err := findMDCollisionFromStates(h, state, dummyState)
+		if err != nil {
+			return nil, err
+		}
+
+		pieces = append(pieces, [2][]byte{shortBlock, append(dummyBlocks, longBlock...)})
+		state = nextState
+	}
+
+	return &ExpandableMessage{pieces: pieces, finalState: state}, nil
+}
+
+// FinalState - returns the state reached by every message of the expandable message
+func (e *ExpandableMessage) FinalState() []byte {
+	return append([]byte{}, e.finalState...)
+}
+
+// Message - returns the expandable message instance of the given length in blocks
+func (e *ExpandableMessage) Message(blocks int) ([]byte, error) {
+	k := len(e.pieces)
+	extraBlocks := blocks - k
+	if extraBlocks < 0 || extraBlocks >= 1<<uint(k) {
+		return nil, errors.New("length out of the expandable message range")
+	}
+
+	var message []byte
+	for i, piece := range e.pieces {
+		// piece i long version adds 2^(k-1-i) blocks
+		if extraBlocks&(1<<uint(k-1-i)) != 0 {
+			message = append(message, piece[1]...)
+		} else {
+			message = append(message, piece[0]...)
+		}
+	}
+
+	return message, nil
+}
+
+// SecondPreimageAttack - Kelsey-Schneier second preimage attack on long
+// messages: builds an expandable message, finds a bridge block from its final
+// state to one of the target intermediate states and fills the gap with the
+// expandable message of the right length. Returns a different message of the
+// same length and hash as target, and the compression function calls it took
+func SecondPreimageAttack(h *MDHash, target []byte) ([]byte, uint64, error) {
+	startCalls := h.Calls()
+
+	blocks := len(target) / MDBlockSize
+	k := 0
+	for 1<<uint(k+1) <= blocks {
+		k++
+	}
+	if k < 1 || blocks < k+1 {
+		return nil, 0, errors.New("target message too short")
+	}
+
+	// intermediate states H_j after j blocks that a bridge can land on,
+	// the expandable message must then provide j-1 blocks
+	maxPrefix := k + 1<<uint(k) - 1
+	intermediateStates := make(map[string]int)
+	state := h.InitialState()
+	for j := 1; j <= blocks; j++ {
+		state = h.Compress(state, target[(j-1)*MDBlockSize:j*MDBlockSize])
+		if j-1 >= k && j-1 <= maxPrefix {
+			if _, ok := intermediateStates[string(state)]; !ok {
+				intermediateStates[string(state)] = j
+			}
+		}
+	}
+
+	expandable, err := NewExpandableMessage(h, k)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for {
+		bridge, err := randomBlock()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		j, ok := intermediateStates[string(h.Compress(expandable.FinalState(), bridge))]
+		if !ok {
+			continue
+		}
+
+		prefix, err := expandable.Message(j - 1)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		forged := append(append(prefix, bridge...), target[j*MDBlockSize:]...)
+		return forged, h.Calls() - startCalls, nil
+	}
+}
+
+// DiamondStructure - binary tree of colliding blocks, from 2^k leaf states
+// any of them leads to the root state following k blocks
+type DiamondStructure struct {
+	StateBits int `json:"stateBits"`
+
+	// States[i] holds the 2^(k-i) states of level i, States[k][0] is the root
+	States [][][]byte `json:"states"`
+
+	// Blocks[i][j] takes States[i][j] to States[i+1][j/2]
+	Blocks [][][]byte `json:"blocks"`
+}
+
+// randomDiamondLeaves - returns n distinct random states
+func randomDiamondLeaves(h *MDHash, n int) ([][]byte, error) {
+	if n > 1<<uint(h.StateBits()-1) {
+		return nil, errors.New("too many leaves for the hash state size")
+	}
+
+	leaves := make([][]byte, 0, n)
+	seen := make(map[string]bool)
+	for len(leaves) < n {
+		state := make([]byte, h.StateSize())
+		if _, err := rand.Read(state); err != nil {
+			return nil, err
+		}
+		state = h.truncate(state)
+
+		if !seen[string(state)] {
+			seen[string(state)] = true
+			leaves = append(leaves, state)
+		}
+	}
+
+	return leaves, nil
+}
+
+// BuildDiamondStructure - builds a diamond structure of 2^k leaves, each level
+// pairs up the states of the previous one and finds a collision for each pair.
+// Pairs are processed in parallel by the given number of workers
+func BuildDiamondStructure(h *MDHash, k, workers int) (*DiamondStructure, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	leaves, err := randomDiamondLeaves(h, 1<<uint(k))
+	if err != nil {
+		return nil, err
+	}
+
+	diamond := &DiamondStructure{StateBits: h.StateBits(), States: [][][]byte{leaves}}
+
+	for level := 0; level < k; level++ {
+		states := diamond.States[level]
+		nextStates := make([][]byte, len(states)/2)
+		blocks := make([][]byte, len(states))
+
+		pairs := make(chan int)
+		errs := make(chan error, workers)
+		// closed on the first error so no more pairs are handed out
+		failed := make(chan struct{})
+		var failOnce sync.Once
+		var wg sync.WaitGroup
+
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for pair := range pairs {
+					block1, block2, nextState, err := findMDCollisionFromStates(h, states[2*pair], states[2*pair+1])
+					if err != nil {
+						errs <- err
+						failOnce.Do(func() { close(failed) })
+						return
+					}
+					// every pair writes its own slots, no locking needed
+					blocks[2*pair], blocks[2*pair+1], nextStates[pair] = block1, block2, nextState
+				}
+			}()
+		}
+
+	feed:
+		for pair := range nextStates {
+			select {
+			case pairs <- pair:
+			case <-failed:
+				break feed
+			}
+		}
+		close(pairs)
+		wg.Wait()
+
+		select {
+		case err := <-errs:
+			return nil, err
+		default:
+		}
+
+		diamond.Blocks = append(diamond.Blocks, blocks)
+		diamond.States = append(diamond.States, nextStates)
+	}
+
+	return diamond, nil
+}
+
+// K - returns the number of levels of the diamond structure
+func (d *DiamondStructure) K() int {
+	return len(d.Blocks)
+}
+
+// Root - returns the state every leaf leads to
+func (d *DiamondStructure) Root() []byte {
+	return d.States[d.K()][0]
+}
+
+// Save - writes the diamond structure to path so it can be reused
+func (d *DiamondStructure) Save(path string) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadDiamondStructure - reads a diamond structure previously saved to path
+func LoadDiamondStructure(path string) (*DiamondStructure, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	diamond := &DiamondStructure{}
+	if err := json.Unmarshal(data, diamond); err != nil {
+		return nil, err
+	}
+	if len(diamond.States) != len(diamond.Blocks)+1 {
+		return nil, errors.New("invalid diamond structure")
+	}
+
+	return diamond, nil
+}
+
+// HerdingMessageSize - size in bytes of the herded messages for prefixes of
+// up to prefixBlocks blocks: prefix, linking block and a path to the root
+func (d *DiamondStructure) HerdingMessageSize(prefixBlocks int) int {
+	return (prefixBlocks + 1 + d.K()) * MDBlockSize
+}
+
+// Prediction - returns the hash published in advance, the one of any
+// herded message built with a prefix of prefixBlocks blocks
+func (d *DiamondStructure) Prediction(h *MDHash, prefixBlocks int) ([]byte, error) {
+	if h.StateBits() != d.StateBits {
+		return nil, errors.New("diamond structure built for another state size")
+	}
+
+	// herded messages are block aligned, the padding is a block on its own
+	padding := MDPad(nil, d.HerdingMessageSize(prefixBlocks))
+	return h.Iterate(d.Root(), padding), nil
+}
+
+// HerdingAttack - returns a message starting with prefix whose hash is the
+// diamond structure prediction: the prefix padded with spaces to prefixBlocks
+// blocks, a linking block to one of the leaves and the path from it to the root
+func HerdingAttack(h *MDHash, d *DiamondStructure, prefix []byte, prefixBlocks int) ([]byte, error) {
+	if h.StateBits() != d.StateBits {
+		return nil, errors.New("diamond structure built for another state size")
+	}
+	if len(prefix) > prefixBlocks*MDBlockSize {
+		return nil, errors.New("prefix too long")
+	}
+
+	message := append([]byte{}, prefix...)
+	for len(message) < prefixBlocks*MDBlockSize {
+		message = append(message, ' ')
+	}
+	prefixState := h.Iterate(h.InitialState(), message)
+
+	leaves := make(map[string]int)
+	for i, leaf := range d.States[0] {
+		leaves[string(leaf)] = i
+	}
+
+	for {
+		linkingBlock, err := randomBlock()
+		if err != nil {
+			return nil, err
+		}
+
+		leaf, ok := leaves[string(h.Compress(prefixState, linkingBlock))]
+		if !ok {
+			continue
+		}
+
+		message = append(message, linkingBlock...)
+		for level, node := 0, leaf; level < d.K(); level, node = level+1, node/2 {
+			message = append(message, d.Blocks[level][node]...)
+		}
+		return message, nil
+	}
+}
@@ -0,0 +1,350 @@
+package cryptochallenges
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ka3de/go-cryptochallenges/md4"
+)
+
+func TestCBCMACHash(t *testing.T) {
+	// given
+	snippet := []byte("alert('MZA who was that?');\n")
+	key := []byte("YELLOW SUBMARINE")
+	expectedHash := "296b8d7cb78a243dda4d0a61d33bbdd1"
+
+	// when
+	hash, err := CBCMACHash(snippet, key)
+	if err != nil {
+		t.Fatalf("Error hashing snippet: %s", err.Error())
+	}
+
+	// then
+	if hex.EncodeToString(hash) != expectedHash {
+		t.Errorf("CBCMACHash(%q) = %x, expected %s", snippet, hash, expectedHash)
+	}
+}
+
+func TestForgeTransferRequest(t *testing.T) {
+	// given
+	api, err := NewMoneyTransferAPI()
+	if err != nil {
+		t.Fatalf("Error creating API: %s", err.Error())
+	}
+
+	request, err := api.NewTransferRequest(Transfer{From: 1, To: 2, Amount: 1000})
+	if err != nil {
+		t.Fatalf("Error creating transfer request: %s", err.Error())
+	}
+	expectedTransfer := Transfer{From: 1, To: 7, Amount: 9999}
+
+	// when
+	forgedRequest, err := ForgeTransferRequest(request, expectedTransfer.To, expectedTransfer.Amount)
+	if err != nil {
+		t.Fatalf("Error forging transfer request: %s", err.Error())
+	}
+
+	transfer, err := api.ProcessTransferRequest(forgedRequest)
+	if err != nil {
+		t.Fatalf("Error processing forged request: %s", err.Error())
+	}
+
+	// then
+	if transfer != expectedTransfer {
+		t.Errorf("ForgeTransferRequest(...) processed as %+v, expected %+v", transfer, expectedTransfer)
+	}
+}
+
+func TestExtendMultiTransferRequest(t *testing.T) {
+	// given
+	api, err := NewMoneyTransferAPI()
+	if err != nil {
+		t.Fatalf("Error creating API: %s", err.Error())
+	}
+
+	victim, attacker := 1, 3
+	victimRequest, err := api.NewMultiTransferRequest(victim, []Transfer{{To: 2, Amount: 100}, {To: 4, Amount: 250}})
+	if err != nil {
+		t.Fatalf("Error creating victim request: %s", err.Error())
+	}
+
+	// the attacker first block ("from=3&tx_list=3") becomes garbage,
+	// the transaction right after the first ';' survives untouched
+	attackerRequest, err := api.NewMultiTransferRequest(attacker,
+		[]Transfer{{To: attacker, Amount: 1}, {To: attacker, Amount: 1000000}})
+	if err != nil {
+		t.Fatalf("Error creating attacker request: %s", err.Error())
+	}
+	expectedTransfer := Transfer{From: victim, To: attacker, Amount: 1000000}
+
+	// when
+	forgedRequest, err := ExtendMultiTransferRequest(victimRequest, attackerRequest)
+	if err != nil {
+		t.Fatalf("Error forging request: %s", err.Error())
+	}
+
+	transfers, err := api.ProcessMultiTransferRequest(forgedRequest)
+	if err != nil {
+		t.Fatalf("Error processing forged request: %s", err.Error())
+	}
+
+	// then
+	found := false
+	for _, transfer := range transfers {
+		found = found || transfer == expectedTransfer
+	}
+	if !found {
+		t.Errorf("ExtendMultiTransferRequest(...) processed as %+v, expected to contain %+v",
+			transfers, expectedTransfer)
+	}
+}
+
+func TestForgeJavaScriptCBCMACCollision(t *testing.T) {
+	// given
+	original := []byte("alert('MZA who was that?');\n")
+	payload := []byte("alert('Ayo, the Wu is back!');")
+	key := []byte("YELLOW SUBMARINE")
+
+	// when
+	forged, err := ForgeJavaScriptCBCMACCollision(original, payload, key)
+	if err != nil {
+		t.Fatalf("Error forging snippet: %s", err.Error())
+	}
+
+	originalHash, err := CBCMACHash(original, key)
+	if err != nil {
+		t.Fatalf("Error hashing original snippet: %s", err.Error())
+	}
+	forgedHash, err := CBCMACHash(forged, key)
+	if err != nil {
+		t.Fatalf("Error hashing forged snippet: %s", err.Error())
+	}
+
+	// then
+	if !bytes.HasPrefix(forged, append(payload, "//"...)) {
+		t.Errorf("ForgeJavaScriptCBCMACCollision(...) = %q, expected to start with the payload", forged)
+	}
+	if !bytes.Equal(forgedHash, originalHash) {
+		t.Errorf("ForgeJavaScriptCBCMACCollision(...) hash = %x, expected %x", forgedHash, originalHash)
+	}
+}
+
+func TestCompressionRatioAttack(t *testing.T) {
+	// given
+	sessionID := "TmV2ZXIgcmV2ZWFsIHRoZSBXdS1UYW5nIFNlY3JldCE="
+	oracles := map[string]CompressionOracle{
+		"stream": NewStreamCompressionOracle(sessionID),
+		"cbc":    NewCBCCompressionOracle(sessionID),
+	}
+
+	for name, oracle := range oracles {
+		// when
+		recovered, err := CompressionRatioAttack(oracle)
+		if err != nil {
+			t.Fatalf("Error running compression attack (%s): %s", name, err.Error())
+		}
+
+		// then
+		if recovered != sessionID {
+			t.Errorf("CompressionRatioAttack(...) (%s) = %s, expected %s", name, recovered, sessionID)
+		}
+	}
+}
+
+func newTestMDHash(t *testing.T, stateBits int, initialState []byte) *MDHash {
+	h, err := NewMDHash(stateBits, initialState)
+	if err != nil {
+		t.Fatalf("Error creating hash: %s", err.Error())
+	}
+	return h
+}
+
+func TestJouxMulticollision(t *testing.T) {
+	// given
+	h := newTestMDHash(t, 16, []byte{0x01, 0x23})
+	n := 5
+
+	// when
+	collisions, _, err := JouxMulticollision(h, n)
+	if err != nil {
+		t.Fatalf("Error generating multicollision: %s", err.Error())
+	}
+	messages := MulticollisionMessages(collisions)
+
+	// then
+	if len(messages) != 1<<uint(n) {
+		t.Fatalf("MulticollisionMessages(...) returned %d messages, expected %d", len(messages), 1<<uint(n))
+	}
+
+	seen := make(map[string]bool)
+	expectedHash := h.Sum(messages[0])
+	for _, message := range messages {
+		if hash := h.Sum(message); !bytes.Equal(hash, expectedHash) {
+			t.Errorf("Hash of %x = %x, expected %x", message, hash, expectedHash)
+		}
+		seen[string(message)] = true
+	}
+	if len(seen) != len(messages) {
+		t.Errorf("JouxMulticollision(...) produced %d distinct messages, expected %d", len(seen), len(messages))
+	}
+}
+
+func TestConcatenatedHashCollision(t *testing.T) {
+	// given
+	cheap := newTestMDHash(t, 16, []byte{0x01, 0x23})
+	expensive := newTestMDHash(t, 24, []byte{0x45, 0x67, 0x89})
+
+	// when
+	message1, message2, err := ConcatenatedHashCollision(cheap, expensive)
+	if err != nil {
+		t.Fatalf("Error finding collision: %s", err.Error())
+	}
+
+	// then
+	if bytes.Equal(message1, message2) {
+		t.Fatalf("ConcatenatedHashCollision(...) returned the same message twice")
+	}
+	if !bytes.Equal(cheap.Sum(message1), cheap.Sum(message2)) ||
+		!bytes.Equal(expensive.Sum(message1), expensive.Sum(message2)) {
+		t.Errorf("ConcatenatedHashCollision(...) messages %x and %x do not collide", message1, message2)
+	}
+	t.Logf("cheap hash calls: %d, expensive hash calls: %d", cheap.Calls(), expensive.Calls())
+}
+
+func TestExpandableMessage(t *testing.T) {
+	// given
+	h := newTestMDHash(t, 16, []byte{0x01, 0x23})
+	k := 4
+
+	// when
+	expandable, err := NewExpandableMessage(h, k)
+	if err != nil {
+		t.Fatalf("Error building expandable message: %s", err.Error())
+	}
+
+	// then
+	for blocks := k; blocks < k+1<<uint(k); blocks++ {
+		message, err := expandable.Message(blocks)
+		if err != nil {
+			t.Fatalf("Error building %d blocks message: %s", blocks, err.Error())
+		}
+
+		if len(message) != blocks*MDBlockSize {
+			t.Errorf("Message(%d) is %d bytes long, expected %d", blocks, len(message), blocks*MDBlockSize)
+		}
+		if state := h.Iterate(h.InitialState(), message); !bytes.Equal(state, expandable.FinalState()) {
+			t.Errorf("Message(%d) leads to state %x, expected %x", blocks, state, expandable.FinalState())
+		}
+	}
+}
+
+func TestSecondPreimageAttack(t *testing.T) {
+	// given
+	h := newTestMDHash(t, 24, []byte{0x01, 0x23, 0x45})
+	target := make([]byte, (1<<12)*MDBlockSize+5)
+	if _, err := rand.Read(target); err != nil {
+		t.Fatalf("Error generating target message: %s", err.Error())
+	}
+
+	// when
+	forged, calls, err := SecondPreimageAttack(h, target)
+	if err != nil {
+		t.Fatalf("Error running second preimage attack: %s", err.Error())
+	}
+
+	// then
+	if bytes.Equal(forged, target) {
+		t.Fatalf("SecondPreimageAttack(...) returned the target message")
+	}
+	if len(forged) != len(target) {
+		t.Errorf("SecondPreimageAttack(...) length = %d, expected %d", len(forged), len(target))
+	}
+	if !bytes.Equal(h.Sum(forged), h.Sum(target)) {
+		t.Errorf("SecondPreimageAttack(...) hash = %x, expected %x", h.Sum(forged), h.Sum(target))
+	}
+	t.Logf("second preimage found with %d compression function calls", calls)
+}
+
+func TestHerdingAttack(t *testing.T) {
+	// given
+	h := newTestMDHash(t, 24, []byte{0x01, 0x23, 0x45})
+	prefixBlocks := 4
+	prefix := []byte("Final score: Real Madrid 3 - Barcelona 1")
+	path := filepath.Join(t.TempDir(), "diamond.json")
+
+	diamond, err := BuildDiamondStructure(h, 8, runtime.NumCPU())
+	if err != nil {
+		t.Fatalf("Error building diamond structure: %s", err.Error())
+	}
+	prediction, err := diamond.Prediction(h, prefixBlocks)
+	if err != nil {
+		t.Fatalf("Error computing prediction: %s", err.Error())
+	}
+	if err := diamond.Save(path); err != nil {
+		t.Fatalf("Error saving diamond structure: %s", err.Error())
+	}
+
+	// when
+	loadedDiamond, err := LoadDiamondStructure(path)
+	if err != nil {
+		t.Fatalf("Error loading diamond structure: %s", err.Error())
+	}
+	message, err := HerdingAttack(h, loadedDiamond, prefix, prefixBlocks)
+	if err != nil {
+		t.Fatalf("Error herding prefix: %s", err.Error())
+	}
+
+	// then
+	if !bytes.HasPrefix(message, prefix) {
+		t.Errorf("HerdingAttack(...) = %q, expected to start with %q", message, prefix)
+	}
+	if hash := h.Sum(message); !bytes.Equal(hash, prediction) {
+		t.Errorf("HerdingAttack(...) hash = %x, expected prediction %x", hash, prediction)
+	}
+}
+
+func TestMD4Collision(t *testing.T) {
+	// when
+	message, collidingMessage, _, err := MD4Collision()
+	if err != nil {
+		t.Fatalf("Error searching MD4 collision: %s", err.Error())
+	}
+
+	// then
+	if bytes.Equal(message, collidingMessage) {
+		t.Fatalf("MD4Collision() returned identical messages %x", message)
+	}
+	if !md4ConditionsHold(md4.DecodeBlock(message)) {
+		t.Errorf("MD4Collision() message %x does not meet the sufficient conditions", message)
+	}
+	if hash, collidingHash := md4.Sum(message), md4.Sum(collidingMessage); hash != collidingHash {
+		t.Errorf("MD4Collision() hashes = %x and %x, expected equal", hash, collidingHash)
+	}
+}
+
+func TestRC4BiasAttack(t *testing.T) {
+	if testing.Short() {
+		t.Skip("2^22 RC4 encryptions for each of the 4 aiming paddings, skipped in short mode")
+	}
+
+	// given
+	// a short secret keeps the number of queries manageable, each of its
+	// bytes is still aimed at both biased positions
+	cookie := []byte("BE")
+	oracle := NewRC4CookieOracle(cookie)
+
+	// when
+	recovered, err := RC4BiasAttack(oracle, 1<<22, runtime.NumCPU())
+	if err != nil {
+		t.Fatalf("Error recovering cookie: %s", err.Error())
+	}
+
+	// then
+	if !bytes.Equal(recovered, cookie) {
+		t.Errorf("RC4BiasAttack(...) = %q, expected %q", recovered, cookie)
+	}
+}
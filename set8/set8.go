@@ -0,0 +1,757 @@
+package cryptochallenges
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/ka3de/go-cryptochallenges/ec"
+	"github.com/ka3de/go-cryptochallenges/ecdsa"
+	"github.com/ka3de/go-cryptochallenges/lll"
+	"github.com/ka3de/go-cryptochallenges/rsa"
+)
+
+// ecdhMessage - message Bob MACs with the ECDH shared key on every exchange
+var ecdhMessage = []byte("crazy flamboyant for the rap enjoyment")
+
+// pointBytes - returns the concatenated coordinates of p, empty for the
+// point at infinity
+func pointBytes(p ec.Point) []byte {
+	if p.IsInfinity() {
+		return nil
+	}
+	return append(p.X.Bytes(), p.Y.Bytes()...)
+}
+
+// ecdhMAC - returns the HMAC-SHA256 of message under the SHA-256 of the
+// ECDH shared secret
+func ecdhMAC(secret, message []byte) []byte {
+	key := sha256.Sum256(secret)
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// ECDHBob - ECDH party answering any public key with a message MACed under
+// the shared key, optionally checking the public key is on its curve
+type ECDHBob struct {
+	curve          *ec.Curve
+	privateKey     *big.Int
+	PublicKey      ec.Point
+	ValidatePoints bool
+}
+
+// NewECDHBob - returns Bob with a random key pair, not validating points
+func NewECDHBob(curve *ec.Curve) (*ECDHBob, error) {
+	privateKey, publicKey, err := ec.GenerateKey(curve)
+	if err != nil {
+		return nil, err
+	}
+	return &ECDHBob{curve: curve, privateKey: privateKey, PublicKey: publicKey}, nil
+}
+
+// Respond - completes the exchange with peerPublicKey and returns the
+// message and its MAC under the shared key
+func (b *ECDHBob) Respond(peerPublicKey ec.Point) ([]byte, []byte, error) {
+	secret, err := ec.SharedSecret(b.curve, b.privateKey, peerPublicKey, b.ValidatePoints)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ecdhMessage, ecdhMAC(pointBytes(secret), ecdhMessage), nil
+}
+
+// chineseRemainder - returns x mod the product of moduli such that
+// x = residues[i] mod moduli[i], moduli being pairwise coprime
+func chineseRemainder(residues, moduli []*big.Int) (*big.Int, *big.Int) {
+	x, modulus := big.NewInt(0), big.NewInt(1)
+	for i := range residues {
+		// x + modulus * t = residues[i] mod moduli[i]
+		t := new(big.Int).Sub(residues[i], x)
+		t.Mul(t, new(big.Int).ModInverse(modulus, moduli[i]))
+		t.Mod(t, moduli[i])
+
+		x.Add(x, t.Mul(t, modulus))
+		modulus.Mul(modulus, moduli[i])
+	}
+	return x, modulus
+}
+
+// smallFactors - returns the distinct primes below bound dividing n
+func smallFactors(n *big.Int, bound int64) []*big.Int {
+	var factors []*big.Int
+	rest := new(big.Int).Set(n)
+	remainder := new(big.Int)
+
+	for p := int64(2); p < bound; p++ {
+		prime := big.NewInt(p)
+		if remainder.Mod(rest, prime).Sign() != 0 {
+			continue
+		}
+		// trial division in increasing order only finds primes
+		factors = append(factors, prime)
+		for remainder.Mod(rest, prime).Sign() == 0 {
+			rest.Div(rest, prime)
+		}
+	}
+	return factors
+}
+
+// pointOfOrder - returns a random point of prime order r of a curve group
+// of the given order, r dividing it
+func pointOfOrder(curve *ec.Curve, order, r *big.Int) (ec.Point, error) {
+	// remove every r factor, the group may not be cyclic in its r-part
+	cofactor := new(big.Int).Set(order)
+	for new(big.Int).Mod(cofactor, r).Sign() == 0 {
+		cofactor.Div(cofactor, r)
+	}
+
+	for {
+		p, err := curve.RandomPoint()
+		if err != nil {
+			return ec.Point{}, err
+		}
+
+		h := curve.ScalarMult(p, cofactor)
+		if h.IsInfinity() {
+			continue
+		}
+		for next := curve.ScalarMult(h, r); !next.IsInfinity(); next = curve.ScalarMult(h, r) {
+			h = next
+		}
+		return h, nil
+	}
+}
+
+// macResidue - returns k in [0, r) such that the tag is the MAC of message
+// under the key derived from k * h, h being of order r
+func macResidue(curve *ec.Curve, h ec.Point, r *big.Int, message, tag []byte) (*big.Int, error) {
+	candidate := ec.Infinity()
+	for k := int64(0); k < r.Int64(); k++ {
+		if hmac.Equal(ecdhMAC(pointBytes(candidate), message), tag) {
+			return big.NewInt(k), nil
+		}
+		candidate = curve.Add(candidate, h)
+	}
+	return nil, errors.New("residue not found")
+}
+
+// InvalidCurve - curve sharing p and a with the attacked one but with another
+// b, along with its group order
+type InvalidCurve struct {
+	B, Order *big.Int
+}
+
+// CryptopalsInvalidCurves - invalid curves for ec.CryptopalsCurve whose
+// orders have enough small factors to recover a private key
+var CryptopalsInvalidCurves = []InvalidCurve{
+	{big.NewInt(210), ec.BigFromString("233970423115425145550826547352470124412")},
+	{big.NewInt(504), ec.BigFromString("233970423115425145544350131142039591210")},
+	{big.NewInt(727), ec.BigFromString("233970423115425145545378039958152057148")},
+}
+
+// InvalidCurveAttack - recovers the private key of a Bob not validating
+// points: for every small prime r (below factorBound) dividing an invalid
+// curve order, Bob is sent a point of order r on that curve and the MAC he
+// returns reveals his key modulo r, the residues being combined with the CRT
+// once their product exceeds the base point order
+func InvalidCurveAttack(bob *ECDHBob, curve *ec.Curve, invalidCurves []InvalidCurve, factorBound int64) (*big.Int, error) {
+	var residues, moduli []*big.Int
+	modulus := big.NewInt(1)
+
+	for _, invalid := range invalidCurves {
+		invalidCurve := curve.WithB(invalid.B)
+
+		for _, r := range smallFactors(invalid.Order, factorBound) {
+			// every prime is used once, moduli must be coprime
+			if new(big.Int).Mod(modulus, r).Sign() == 0 {
+				continue
+			}
+
+			h, err := pointOfOrder(invalidCurve, invalid.Order, r)
+			if err != nil {
+				return nil, err
+			}
+			message, tag, err := bob.Respond(h)
+			if err != nil {
+				return nil, err
+			}
+			residue, err := macResidue(invalidCurve, h, r, message, tag)
+			if err != nil {
+				return nil, err
+			}
+
+			residues, moduli = append(residues, residue), append(moduli, r)
+			modulus.Mul(modulus, r)
+
+			if modulus.Cmp(curve.N) > 0 {
+				privateKey, _ := chineseRemainder(residues, moduli)
+				if !curve.ScalarBaseMult(privateKey).Equal(bob.PublicKey) {
+					return nil, errors.New("recovered key does not match public key")
+				}
+				return privateKey, nil
+			}
+		}
+	}
+
+	return nil, errors.New("not enough small subgroups to recover the key")
+}
+
+// MontgomeryECDHBob - x-only ECDH party using the Montgomery ladder on the
+// u-coordinates it receives, without checking they are on the curve
+type MontgomeryECDHBob struct {
+	curve      *ec.MontgomeryCurve
+	privateKey *big.Int
+	PublicKey  *big.Int
+}
+
+// NewMontgomeryECDHBob - returns Bob with a random key pair
+func NewMontgomeryECDHBob(curve *ec.MontgomeryCurve) (*MontgomeryECDHBob, error) {
+	privateKey, err := rand.Int(rand.Reader, new(big.Int).Sub(curve.N, big.NewInt(1)))
+	if err != nil {
+		return nil, err
+	}
+	privateKey.Add(privateKey, big.NewInt(1))
+
+	return &MontgomeryECDHBob{
+		curve:      curve,
+		privateKey: privateKey,
+		PublicKey:  curve.Ladder(curve.U, privateKey),
+	}, nil
+}
+
+// Respond - completes the exchange with the peer u-coordinate and returns
+// the message and its MAC under the shared key
+func (b *MontgomeryECDHBob) Respond(peerU *big.Int) ([]byte, []byte) {
+	secret := b.curve.Ladder(peerU, b.privateKey)
+	return ecdhMessage, ecdhMAC(secret.Bytes(), ecdhMessage)
+}
+
+// CryptopalsTwistOrder - order of the quadratic twist of
+// ec.CryptopalsMontgomeryCurve
+var CryptopalsTwistOrder = ec.BigFromString("233970423115425145549737651362517029924")
+
+// twistPointOfOrder - returns the u-coordinate of a random point of order n
+// on the twist, n being a product of distinct odd primes dividing its order
+func twistPointOfOrder(curve *ec.MontgomeryCurve, twistOrder, n *big.Int, primes []*big.Int) (*big.Int, error) {
+	cofactor := new(big.Int).Div(twistOrder, n)
+	for {
+		u, err := curve.RandomTwistU()
+		if err != nil {
+			return nil, err
+		}
+
+		h := curve.Ladder(u, cofactor)
+		orderN := true
+		for _, r := range primes {
+			if curve.Ladder(h, new(big.Int).Div(n, r)).Sign() == 0 {
+				orderN = false
+				break
+			}
+		}
+		if orderN {
+			return h, nil
+		}
+	}
+}
+
+// twistResidue - returns k in [0, r/2] such that the tag is the MAC of
+// message under the key derived from u(k * h), h being of odd prime order r.
+// Multiples are walked with the differential addition
+// u(k+1) = (u(k)u(1) - 1)^2 / (u(k-1) (u(k) - u(1))^2)
+func twistResidue(curve *ec.MontgomeryCurve, h, r *big.Int, message, tag []byte) (*big.Int, error) {
+	p := curve.P
+	if hmac.Equal(ecdhMAC(big.NewInt(0).Bytes(), message), tag) {
+		return big.NewInt(0), nil
+	}
+
+	previous, current := h, curve.Ladder(h, big.NewInt(2))
+	if hmac.Equal(ecdhMAC(h.Bytes(), message), tag) {
+		return big.NewInt(1), nil
+	}
+
+	half := new(big.Int).Rsh(r, 1).Int64()
+	numerator, denominator := new(big.Int), new(big.Int)
+	for k := int64(2); k <= half; k++ {
+		if hmac.Equal(ecdhMAC(current.Bytes(), message), tag) {
+			return big.NewInt(k), nil
+		}
+
+		numerator.Mul(current, h)
+		numerator.Sub(numerator, big.NewInt(1))
+		numerator.Mul(numerator, numerator)
+
+		denominator.Sub(current, h)
+		denominator.Mul(denominator, denominator)
+		denominator.Mul(denominator, previous)
+		denominator.Mod(denominator, p)
+		denominator.ModInverse(denominator, p)
+
+		next := new(big.Int).Mul(numerator, denominator)
+		previous, current = current, next.Mod(next, p)
+	}
+
+	return nil, errors.New("residue not found")
+}
+
+// kangarooAttempts - number of fresh walks tried by pollardKangaroo, a
+// single walk misses the trap in up to one run out of five
+const kangarooAttempts = 8
+
+// pollardKangaroo - returns m in [a, b] such that m * g = y for one of the
+// targets y, along with its index, using Pollard's kangaroo algorithm with a
+// single tame kangaroo trap shared by every target. When every wild kangaroo
+// jumps past the trap, the search is retried with another pseudo-random walk.
+// b - a must be below 2^62
+func pollardKangaroo(curve *ec.Curve, g ec.Point, targets []ec.Point, a, b *big.Int) (*big.Int, int, error) {
+	width := new(big.Int).Sub(b, a)
+	if width.Sign() < 0 || width.BitLen() > 62 {
+		return nil, 0, errors.New("invalid kangaroo interval")
+	}
+
+	// jumps are powers of two, with a mean around sqrt(b - a) / 2
+	meanJump := new(big.Int).Sqrt(width).Uint64()/2 + 1
+	k := uint64(1)
+	for (uint64(1)<<k-1)/k < meanJump {
+		k++
+	}
+	jumps := make([]ec.Point, k)
+	for i := range jumps {
+		jumps[i] = curve.ScalarMult(g, new(big.Int).Lsh(big.NewInt(1), uint(i)))
+	}
+
+	for attempt := uint64(0); attempt < kangarooAttempts; attempt++ {
+		// every attempt maps the points to the jumps differently
+		jumpIndex := func(p ec.Point) uint64 {
+			if p.IsInfinity() {
+				return attempt % k
+			}
+			return (p.X.Uint64() + attempt) % k
+		}
+
+		m, t, found := kangarooWalk(curve, g, targets, b, width.Uint64(), meanJump, jumps, jumpIndex)
+		if found {
+			return m, t, nil
+		}
+	}
+
+	return nil, 0, errors.New("logarithm not found in interval")
+}
+
+// kangarooWalk - single pollardKangaroo attempt with the given jumps and
+// walk, reports whether a wild kangaroo landed on the tame kangaroo trap
+func kangarooWalk(curve *ec.Curve, g ec.Point, targets []ec.Point, b *big.Int, width, meanJump uint64,
+	jumps []ec.Point, jumpIndex func(ec.Point) uint64) (*big.Int, int, bool) {
+	// tame kangaroo from b, leaving a trap where it stops
+	tameDistance := uint64(0)
+	tame := curve.ScalarMult(g, b)
+	for i := uint64(0); i < 4*meanJump; i++ {
+		index := jumpIndex(tame)
+		tameDistance += 1 << index
+		tame = curve.Add(tame, jumps[index])
+	}
+
+	// wild kangaroos from every target, jumping in turns so the one that
+	// lands on the trap does not wait for the others to give up
+	wilds := append([]ec.Point{}, targets...)
+	wildDistances := make([]uint64, len(targets))
+	for running := true; running; {
+		running = false
+		for t := range wilds {
+			if wildDistances[t] > width+tameDistance {
+				continue
+			}
+			running = true
+
+			if wilds[t].Equal(tame) {
+				m := new(big.Int).Add(b, new(big.Int).SetUint64(tameDistance))
+				return m.Sub(m, new(big.Int).SetUint64(wildDistances[t])), t, true
+			}
+
+			index := jumpIndex(wilds[t])
+			wildDistances[t] += 1 << index
+			wilds[t] = curve.Add(wilds[t], jumps[index])
+		}
+	}
+
+	return nil, 0, false
+}
+
+// TwistResidues - returns c and M such that the private key of an x-only Bob
+// accepting points of the quadratic twist is +-c mod M: for every odd prime r
+// (below factorBound) dividing the twist order, a point of order r gives the
+// key modulo r up to its sign. The signs are made consistent by querying
+// points whose order is the product of the moduli so far
+func TwistResidues(bob *MontgomeryECDHBob, curve *ec.MontgomeryCurve, twistOrder *big.Int, factorBound int64) (*big.Int, *big.Int, error) {
+	var primes []*big.Int
+	c, modulus := big.NewInt(0), big.NewInt(1)
+
+	for _, r := range smallFactors(twistOrder, factorBound) {
+		if r.Cmp(big.NewInt(2)) == 0 || new(big.Int).Mod(twistOrder, new(big.Int).Mul(r, r)).Sign() == 0 {
+			continue
+		}
+
+		h, err := twistPointOfOrder(curve, twistOrder, r, []*big.Int{r})
+		if err != nil {
+			return nil, nil, err
+		}
+		message, tag := bob.Respond(h)
+		k, err := twistResidue(curve, h, r, message, tag)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(primes) == 0 {
+			c, modulus, primes = k, r, []*big.Int{r}
+			continue
+		}
+
+		// key = +-c mod modulus and +-k mod r: only one of CRT(c, k) and
+		// CRT(c, -k) is the key up to its sign modulo modulus * r
+		primes = append(primes, r)
+		combined := new(big.Int).Mul(modulus, r)
+		g, err := twistPointOfOrder(curve, twistOrder, combined, primes)
+		if err != nil {
+			return nil, nil, err
+		}
+		message, tag = bob.Respond(g)
+
+		candidate, _ := chineseRemainder([]*big.Int{c, k}, []*big.Int{modulus, r})
+		if !hmac.Equal(ecdhMAC(curve.Ladder(g, candidate).Bytes(), message), tag) {
+			candidate, _ = chineseRemainder([]*big.Int{c, new(big.Int).Sub(r, k)}, []*big.Int{modulus, r})
+		}
+		c, modulus = candidate, combined
+	}
+
+	return c, modulus, nil
+}
+
+// TwistAttack - recovers the private key of an x-only Bob accepting points of
+// the quadratic twist: TwistResidues leaves key = +-c mod M and the rest of
+// the key is found with Pollard's kangaroo on the Weierstrass form of the
+// curve, the public key being known up to its sign too
+func TwistAttack(bob *MontgomeryECDHBob, curve *ec.MontgomeryCurve, twistOrder *big.Int, factorBound int64) (*big.Int, error) {
+	c, modulus, err := TwistResidues(bob, curve, twistOrder, factorBound)
+	if err != nil {
+		return nil, err
+	}
+
+	w := curve.Weierstrass()
+	publicKey, ok := w.PointAt(curve.ToWeierstrassX(bob.PublicKey))
+	if !ok {
+		return nil, errors.New("invalid public key")
+	}
+
+	// key = residue + m * M with key * G = +-publicKey, m in [0, N / M]
+	var residues []*big.Int
+	var targets []ec.Point
+	for _, residue := range []*big.Int{c, new(big.Int).Sub(modulus, c)} {
+		for _, target := range []ec.Point{publicKey, w.Neg(publicKey)} {
+			residues = append(residues, residue)
+			targets = append(targets, w.Add(target, w.Neg(w.ScalarBaseMult(residue))))
+		}
+	}
+
+	m, t, err := pollardKangaroo(w, w.ScalarBaseMult(modulus), targets, big.NewInt(0), new(big.Int).Div(w.N, modulus))
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey := m.Mul(m, modulus)
+	return privateKey.Add(privateKey, residues[t]), nil
+}
+
+// ECDSADuplicateKey - returns a key pair, on a copy of the curve with another
+// generator G', for which (r, s) is also a valid signature of hash: with
+// R = u1 G + u2 Q and a random d', G' = (u1 + u2 d')^-1 R and Q' = d' G'
+func ECDSADuplicateKey(publicKey *ecdsa.PublicKey, hash []byte, r, s *big.Int) (*ecdsa.PrivateKey, error) {
+	curve := publicKey.Curve
+	n := curve.N
+
+	w := new(big.Int).ModInverse(s, n)
+	if w == nil {
+		return nil, errors.New("invalid signature")
+	}
+	u1 := new(big.Int).Mul(ecdsa.HashToInt(hash, n), w)
+	u1.Mod(u1, n)
+	u2 := new(big.Int).Mul(r, w)
+	u2.Mod(u2, n)
+	point := curve.Add(curve.ScalarBaseMult(u1), curve.ScalarMult(publicKey.Q, u2))
+
+	for {
+		d, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, err
+		}
+
+		t := new(big.Int).Mul(u2, d)
+		t.Add(t, u1)
+		tInverse := t.ModInverse(t.Mod(t, n), n)
+		if d.Sign() == 0 || tInverse == nil {
+			continue
+		}
+
+		newCurve := *curve
+		newCurve.G = curve.ScalarMult(point, tInverse)
+		return &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{Curve: &newCurve, Q: newCurve.ScalarBaseMult(d)},
+			D:         d,
+		}, nil
+	}
+}
+
+// smoothPrime - returns a prime p of the given bit size such that p - 1 is
+// 2 times distinct primes below about 2^18, none of them in used, along
+// with the factors of p - 1
+func smoothPrime(bits int, used map[int64]bool) (*big.Int, []*big.Int, error) {
+	const factorBits = 16
+
+	for {
+		product := big.NewInt(2)
+		factors := []*big.Int{big.NewInt(2)}
+		chosen := make(map[int64]bool)
+
+		randomPrime := func(lo, hi int64) (int64, error) {
+			for {
+				f, err := rand.Int(rand.Reader, big.NewInt(hi-lo))
+				if err != nil {
+					return 0, err
+				}
+				candidate := f.Int64() + lo
+				if !used[candidate] && !chosen[candidate] && f.Add(f, big.NewInt(lo)).ProbablyPrime(10) {
+					chosen[candidate] = true
+					return candidate, nil
+				}
+			}
+		}
+
+		// stop below bits - 12 so the last factor range holds enough primes
+		for product.BitLen() < bits-factorBits-2 {
+			room := uint(bits - 12 - product.BitLen())
+			if room > factorBits {
+				room = factorBits
+			}
+			f, err := randomPrime(3, 1<<room)
+			if err != nil {
+				return nil, nil, err
+			}
+			factors = append(factors, big.NewInt(f))
+			product.Mul(product, big.NewInt(f))
+		}
+
+		// last factor brings p - 1 to exactly bits bits
+		lo := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+		lo.Div(lo, product)
+		hi := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+		hi.Div(hi, product)
+		f, err := randomPrime(lo.Int64()+1, hi.Int64())
+		if err != nil {
+			return nil, nil, err
+		}
+		factors = append(factors, big.NewInt(f))
+		product.Mul(product, big.NewInt(f))
+
+		p := product.Add(product, big.NewInt(1))
+		if p.BitLen() == bits && p.ProbablyPrime(20) {
+			return p, factors, nil
+		}
+	}
+}
+
+// isGenerator - returns whether g generates the multiplicative group mod p,
+// factors being the distinct prime factors of p - 1
+func isGenerator(g, p *big.Int, factors []*big.Int) bool {
+	order := new(big.Int).Sub(p, big.NewInt(1))
+	for _, f := range factors {
+		if new(big.Int).Exp(g, new(big.Int).Div(order, f), p).Cmp(big.NewInt(1)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// pohligHellman - returns x mod p - 1 such that g^x = y mod p, p - 1 being
+// the product of the distinct small primes in factors: the logarithm modulo
+// every factor f is brute forced in the subgroup of order f
+func pohligHellman(g, y, p *big.Int, factors []*big.Int) (*big.Int, error) {
+	order := new(big.Int).Sub(p, big.NewInt(1))
+	residues := make([]*big.Int, len(factors))
+
+	for i, f := range factors {
+		cofactor := new(big.Int).Div(order, f)
+		gf := new(big.Int).Exp(g, cofactor, p)
+		yf := new(big.Int).Exp(y, cofactor, p)
+
+		current := big.NewInt(1)
+		for x := int64(0); x < f.Int64(); x++ {
+			if current.Cmp(yf) == 0 {
+				residues[i] = big.NewInt(x)
+				break
+			}
+			current.Mul(current, gf)
+			current.Mod(current, p)
+		}
+		if residues[i] == nil {
+			return nil, errors.New("logarithm not found")
+		}
+	}
+
+	x, _ := chineseRemainder(residues, factors)
+	return x, nil
+}
+
+// RSADuplicateKey - returns a public key other than publicKey for which the
+// PKCS#1 v1.5 signature of hashed also verifies: N' = pq with p - 1 and q - 1
+// smooth and the signature generating both groups, so that the exponent e'
+// with signature^e' = encoded message mod N' is found with Pohlig-Hellman
+func RSADuplicateKey(publicKey *rsa.PublicKey, hash crypto.Hash, hashed []byte, signature *big.Int) (*rsa.PublicKey, error) {
+	encoded, err := rsa.EncodePKCS1v15Signature(hash, hashed, publicKey.Size())
+	if err != nil {
+		return nil, err
+	}
+	message := new(big.Int).SetBytes(encoded)
+	bits := publicKey.N.BitLen()
+
+	for {
+		used := make(map[int64]bool)
+		p, pFactors, err := smoothPrime(bits/2, used)
+		if err != nil {
+			return nil, err
+		}
+		if !isGenerator(signature, p, pFactors) {
+			continue
+		}
+		// q - 1 shares no factor but 2 with p - 1, so the CRT applies
+		for _, f := range pFactors[1:] {
+			used[f.Int64()] = true
+		}
+
+		qBits := bits - p.BitLen() + 1
+		q, qFactors, err := smoothPrime(qBits, used)
+		if err != nil {
+			return nil, err
+		}
+		n := new(big.Int).Mul(p, q)
+		if n.BitLen() != bits || n.Cmp(signature) <= 0 || !isGenerator(signature, q, qFactors) {
+			continue
+		}
+
+		ep, err := pohligHellman(signature, message, p, pFactors)
+		if err != nil {
+			return nil, err
+		}
+		eq, err := pohligHellman(signature, message, q, qFactors)
+		if err != nil {
+			return nil, err
+		}
+		// both exponents are consistent modulo the shared factor 2
+		if ep.Bit(0) != eq.Bit(0) {
+			continue
+		}
+
+		halfQOrder := new(big.Int).Rsh(new(big.Int).Sub(q, big.NewInt(1)), 1)
+		e, _ := chineseRemainder(
+			[]*big.Int{ep, new(big.Int).Mod(eq, halfQOrder)},
+			[]*big.Int{new(big.Int).Sub(p, big.NewInt(1)), halfQOrder})
+		return &rsa.PublicKey{N: n, E: e}, nil
+	}
+}
+
+// ECDSASignedMessage - message hash along with its ECDSA signature
+type ECDSASignedMessage struct {
+	Hash []byte
+	R    *big.Int
+	S    *big.Int
+}
+
+// SignWithBiasedNonce - signs hash with a random nonce whose zeroBits least
+// significant bits are zero, as a flawed signer would
+func SignWithBiasedNonce(privateKey *ecdsa.PrivateKey, hash []byte, zeroBits uint) (*big.Int, *big.Int, error) {
+	for {
+		k, err := rand.Int(rand.Reader, privateKey.Curve.N)
+		if err != nil {
+			return nil, nil, err
+		}
+		k.Rsh(k, zeroBits).Lsh(k, zeroBits)
+		if k.Sign() == 0 {
+			continue
+		}
+
+		r, s, err := ecdsa.SignWithNonce(privateKey, hash, k)
+		if err == nil {
+			return r, s, nil
+		}
+	}
+}
+
+// BiasedNonceAttack - recovers an ECDSA private key from signatures whose
+// nonces have their zeroBits low bits zeroed. With k = 2^l b, every signature
+// gives b = d t + u mod n with t = r / (s 2^l), u = H(m) / (s 2^l) and b below
+// n / 2^l, a hidden number problem solved by reducing the basis
+//
+//	n  0 ...  0  0    0
+//	0  n ...  0  0    0
+//	     ...
+//	t1 t2 ... tm ct   0
+//	u1 u2 ... um 0    cu
+//
+// with ct = 1 / 2^l and cu = n / 2^l: a short vector ending with cu holds
+// -d ct just before it
+func BiasedNonceAttack(publicKey *ecdsa.PublicKey, signatures []ECDSASignedMessage, zeroBits uint) (*big.Int, error) {
+	curve := publicKey.Curve
+	n := curve.N
+	m := len(signatures)
+	shift := new(big.Int).Lsh(big.NewInt(1), zeroBits)
+
+	basis := make([][]*big.Rat, m+2)
+	for i := range basis {
+		basis[i] = make([]*big.Rat, m+2)
+		for j := range basis[i] {
+			basis[i][j] = new(big.Rat)
+		}
+	}
+	for i := 0; i < m; i++ {
+		basis[i][i].SetInt(n)
+	}
+
+	for i, signature := range signatures {
+		// 1 / (s 2^l) mod n
+		inverse := new(big.Int).Mul(signature.S, shift)
+		if inverse.ModInverse(inverse.Mod(inverse, n), n) == nil {
+			return nil, errors.New("invalid signature")
+		}
+
+		t := new(big.Int).Mul(signature.R, inverse)
+		basis[m][i].SetInt(t.Mod(t, n))
+		u := new(big.Int).Mul(ecdsa.HashToInt(signature.Hash, n), inverse)
+		basis[m+1][i].SetInt(u.Mod(u, n))
+	}
+	ct := new(big.Rat).SetFrac(big.NewInt(1), shift)
+	cu := new(big.Rat).SetFrac(n, shift)
+	basis[m][m].Set(ct)
+	basis[m+1][m+1].Set(cu)
+
+	reduced := lll.Reduce(basis, big.NewRat(99, 100))
+
+	for _, row := range reduced {
+		if new(big.Rat).Abs(row[m+1]).Cmp(cu) != 0 {
+			continue
+		}
+
+		// the row may be the negated vector
+		candidate := new(big.Rat).Quo(row[m], ct)
+		if !candidate.IsInt() {
+			continue
+		}
+		for _, d := range []*big.Int{new(big.Int).Neg(candidate.Num()), candidate.Num()} {
+			d.Mod(d, n)
+			if curve.ScalarBaseMult(d).Equal(publicKey.Q) {
+				return d, nil
+			}
+		}
+	}
+
+	return nil, errors.New("private key not found in the reduced basis")
+}
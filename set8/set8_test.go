@@ -0,0 +1,198 @@
+package cryptochallenges
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ka3de/go-cryptochallenges/ec"
+	"github.com/ka3de/go-cryptochallenges/ecdsa"
+	"github.com/ka3de/go-cryptochallenges/rsa"
+)
+
+func TestInvalidCurveAttack(t *testing.T) {
+	// given
+	bob, err := NewECDHBob(ec.CryptopalsCurve)
+	if err != nil {
+		t.Fatalf("Error creating Bob: %s", err.Error())
+	}
+
+	// when
+	privateKey, err := InvalidCurveAttack(bob, ec.CryptopalsCurve, CryptopalsInvalidCurves, 1<<16)
+	if err != nil {
+		t.Fatalf("Error recovering private key: %s", err.Error())
+	}
+
+	// then
+	if privateKey.Cmp(bob.privateKey) != 0 {
+		t.Errorf("InvalidCurveAttack(...) = %s, expected %s", privateKey, bob.privateKey)
+	}
+}
+
+func TestInvalidCurveAttackValidatingBob(t *testing.T) {
+	// given
+	bob, err := NewECDHBob(ec.CryptopalsCurve)
+	if err != nil {
+		t.Fatalf("Error creating Bob: %s", err.Error())
+	}
+	bob.ValidatePoints = true
+
+	// when
+	_, err = InvalidCurveAttack(bob, ec.CryptopalsCurve, CryptopalsInvalidCurves, 1<<16)
+
+	// then
+	if err == nil {
+		t.Errorf("InvalidCurveAttack(validating Bob) succeeded, expected an error")
+	}
+}
+
+func TestPollardKangaroo(t *testing.T) {
+	// given
+	c := ec.CryptopalsCurve
+	g := c.ScalarBaseMult(big.NewInt(1000003))
+	a, b := big.NewInt(1<<20), big.NewInt(1<<26)
+	m := big.NewInt(34567890)
+	targets := []ec.Point{c.ScalarBaseMult(big.NewInt(42)), c.ScalarMult(g, m)}
+
+	// when
+	found, index, err := pollardKangaroo(c, g, targets, a, b)
+	if err != nil {
+		t.Fatalf("Error computing logarithm: %s", err.Error())
+	}
+
+	// then
+	if index != 1 || found.Cmp(m) != 0 {
+		t.Errorf("pollardKangaroo(...) = %s (target %d), expected %s (target 1)", found, index, m)
+	}
+}
+
+func TestTwistResidues(t *testing.T) {
+	// given
+	bob, err := NewMontgomeryECDHBob(ec.CryptopalsMontgomeryCurve)
+	if err != nil {
+		t.Fatalf("Error creating Bob: %s", err.Error())
+	}
+
+	// when
+	c, modulus, err := TwistResidues(bob, ec.CryptopalsMontgomeryCurve, CryptopalsTwistOrder, 1<<22)
+	if err != nil {
+		t.Fatalf("Error recovering residues: %s", err.Error())
+	}
+
+	// then
+	residue := new(big.Int).Mod(bob.privateKey, modulus)
+	if residue.Cmp(c) != 0 && residue.Cmp(new(big.Int).Sub(modulus, c)) != 0 {
+		t.Errorf("TwistResidues(...) = +-%s mod %s, expected +-%s", c, modulus, residue)
+	}
+}
+
+func TestTwistAttack(t *testing.T) {
+	if testing.Short() {
+		t.Skip("kangaroo interval around 2^40, several minutes on a single core, skipped in short mode")
+	}
+
+	// given
+	bob, err := NewMontgomeryECDHBob(ec.CryptopalsMontgomeryCurve)
+	if err != nil {
+		t.Fatalf("Error creating Bob: %s", err.Error())
+	}
+
+	// when
+	privateKey, err := TwistAttack(bob, ec.CryptopalsMontgomeryCurve, CryptopalsTwistOrder, 1<<22)
+	if err != nil {
+		t.Fatalf("Error recovering private key: %s", err.Error())
+	}
+
+	// then
+	if privateKey.Cmp(bob.privateKey) != 0 {
+		t.Errorf("TwistAttack(...) = %s, expected %s", privateKey, bob.privateKey)
+	}
+}
+
+func TestECDSADuplicateKey(t *testing.T) {
+	// given
+	privateKey, err := ecdsa.GenerateKey(ec.CryptopalsCurve)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	hash := sha256.Sum256([]byte("I'm the one and only key holder"))
+	r, s, err := ecdsa.Sign(privateKey, hash[:])
+	if err != nil {
+		t.Fatalf("Error signing message: %s", err.Error())
+	}
+
+	// when
+	duplicateKey, err := ECDSADuplicateKey(&privateKey.PublicKey, hash[:], r, s)
+	if err != nil {
+		t.Fatalf("Error computing duplicate key: %s", err.Error())
+	}
+
+	// then
+	if duplicateKey.Q.Equal(privateKey.Q) {
+		t.Errorf("ECDSADuplicateKey(...) returned the original public key")
+	}
+	if !ecdsa.Verify(&duplicateKey.PublicKey, hash[:], r, s) {
+		t.Errorf("Verify(duplicate key, ...) = false, expected true")
+	}
+	if !duplicateKey.Curve.ScalarBaseMult(duplicateKey.D).Equal(duplicateKey.Q) {
+		t.Errorf("ECDSADuplicateKey(...) private key does not match its public key")
+	}
+}
+
+func TestRSADuplicateKey(t *testing.T) {
+	// given
+	privateKey, err := rsa.GenerateKey(512, 65537)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	hashed := sha256.Sum256([]byte("I'm the one and only key holder"))
+	signature, err := rsa.SignPKCS1v15(privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatalf("Error signing message: %s", err.Error())
+	}
+
+	// when
+	duplicateKey, err := RSADuplicateKey(&privateKey.PublicKey, crypto.SHA256, hashed[:], signature)
+	if err != nil {
+		t.Fatalf("Error computing duplicate key: %s", err.Error())
+	}
+
+	// then
+	if duplicateKey.N.Cmp(privateKey.N) == 0 {
+		t.Errorf("RSADuplicateKey(...) returned the original modulus")
+	}
+	if !rsa.VerifyPKCS1v15(duplicateKey, crypto.SHA256, hashed[:], signature) {
+		t.Errorf("VerifyPKCS1v15(duplicate key, ...) = false, expected true")
+	}
+}
+
+func TestBiasedNonceAttack(t *testing.T) {
+	// given
+	privateKey, err := ecdsa.GenerateKey(ec.CryptopalsCurve)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+
+	signatures := make([]ECDSASignedMessage, 22)
+	for i := range signatures {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("message number %d", i)))
+		r, s, err := SignWithBiasedNonce(privateKey, hash[:], 8)
+		if err != nil {
+			t.Fatalf("Error signing message: %s", err.Error())
+		}
+		signatures[i] = ECDSASignedMessage{Hash: hash[:], R: r, S: s}
+	}
+
+	// when
+	d, err := BiasedNonceAttack(&privateKey.PublicKey, signatures, 8)
+	if err != nil {
+		t.Fatalf("Error recovering private key: %s", err.Error())
+	}
+
+	// then
+	if d.Cmp(privateKey.D) != 0 {
+		t.Errorf("BiasedNonceAttack(...) = %s, expected %s", d, privateKey.D)
+	}
+}
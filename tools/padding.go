@@ -1,5 +1,7 @@
 package tools
 
+import "errors"
+
 func ApplyPkcs7Padding(plaintext []byte, blockSize int) []byte {
 	var lastBlock []byte
 	plaintextBlocksCount := len(plaintext) / blockSize
@@ -21,7 +23,8 @@ func ApplyPkcs7Padding(plaintext []byte, blockSize int) []byte {
 		}
 	}
 
-	return append(plaintext, padding...)
+	// copy the plaintext so its backing array is never overwritten
+	return append(append([]byte{}, plaintext...), padding...)
 }
 
 func RemovePkcs7Padding(plaintext []byte) []byte {
@@ -29,3 +32,21 @@ func RemovePkcs7Padding(plaintext []byte) []byte {
 	paddingSize := int(plaintext[plaintextSize-1])
 	return plaintext[:len(plaintext)-paddingSize]
 }
+
+func RemoveValidPkcs7Padding(plaintext []byte, blockSize int) ([]byte, error) {
+	if len(plaintext) == 0 {
+		return nil, errors.New("invalid padding")
+	}
+
+	paddingSize := int(plaintext[len(plaintext)-1])
+	if paddingSize == 0 || paddingSize > blockSize || paddingSize > len(plaintext) {
+		return nil, errors.New("invalid padding")
+	}
+	for _, b := range plaintext[len(plaintext)-paddingSize:] {
+		if int(b) != paddingSize {
+			return nil, errors.New("invalid padding")
+		}
+	}
+
+	return plaintext[:len(plaintext)-paddingSize], nil
+}
@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestApplyPkcs7PaddingDoesNotAlias(t *testing.T) {
+	// given
+	// the plaintext is a prefix of a larger buffer, as when the MAC is
+	// sliced off the end of a signed request
+	buffer := []byte("YELLOW SUBMARINEsome MAC bytes!!")
+	expectedBuffer := append([]byte{}, buffer...)
+	plaintext := buffer[:16]
+
+	// when
+	ApplyPkcs7Padding(plaintext, 16)
+
+	// then
+	if !bytes.Equal(buffer, expectedBuffer) {
+		t.Errorf("ApplyPkcs7Padding(%s) overwrote the underlying buffer: %q, expected %q",
+			plaintext, buffer, expectedBuffer)
+	}
+}
+
+func TestRemoveValidPkcs7Padding(t *testing.T) {
+	// given
+	valid := []byte("ICE ICE BABY\x04\x04\x04\x04")
+	invalid := [][]byte{
+		[]byte("ICE ICE BABY\x05\x05\x05\x05"),
+		[]byte("ICE ICE BABY\x01\x02\x03\x04"),
+		[]byte("ICE ICE BABY\x00"),
+	}
+	expectedPlaintext := []byte("ICE ICE BABY")
+
+	// when
+	plaintext, err := RemoveValidPkcs7Padding(valid, 16)
+
+	// then
+	if err != nil {
+		t.Errorf("Error removing valid padding: %s", err.Error())
+	}
+	if !bytes.Equal(plaintext, expectedPlaintext) {
+		t.Errorf("RemoveValidPkcs7Padding(%q) = %q, expected %q", valid, plaintext, expectedPlaintext)
+	}
+	for _, data := range invalid {
+		if _, err := RemoveValidPkcs7Padding(data, 16); err == nil {
+			t.Errorf("RemoveValidPkcs7Padding(%q) accepted an invalid padding", data)
+		}
+	}
+}